@@ -16,6 +16,8 @@ func InitConfig() error {
 	viper.AddConfigPath(".")
 	viper.AutomaticEnv()
 	viper.SetDefault("serpapi.api_key", "")
+	viper.SetDefault("scraper.selection_policy", "best")
+	viper.SetDefault("server.addr", ":8080")
 
 	err := viper.BindEnv("serpapi.api_key", "SERPAPI_KEY")
 	if err != nil {
@@ -1,26 +1,107 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
+	"github.com/Businge931/company-email-scraper/configs"
+	"github.com/Businge931/company-email-scraper/models"
 	"github.com/Businge931/company-email-scraper/scraper"
+	"github.com/Businge931/company-email-scraper/server"
 )
 
+// Time given to requests in flight when the server is stopped
+const shutdownTimeout = 10 * time.Second
+
 func main() {
-	companyNames, err := scraper.ReadCompanyNames("companies-list/input.txt")
+	if err := configs.InitConfig(); err != nil {
+		log.Fatalf("Error initializing configuration: %v", err)
+	}
+
+	// Search API calls and company site fetches can be routed through different proxies
+	searchClient, err := scraper.NewHTTPClientFromConfig(scraper.SearchRequests)
+	if err != nil {
+		log.Fatalf("Failed to create search client: %v", err)
+	}
+
+	httpFetchClient, err := scraper.NewHTTPClientFromConfig(scraper.FetchRequests)
+	if err != nil {
+		log.Fatalf("Failed to create fetch client: %v", err)
+	}
+
+	// robots.txt and Crawl-delay are honoured across all the companies looked up
+	fetchClient := scraper.NewFetchClient(httpFetchClient)
+
+	// MX and SMTP checks of the found emails, as enabled in the configuration
+	checks := scraper.NewEmailChecksFromConfig()
+
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(server.NewFromConfig(searchClient, fetchClient, checks))
+
+		return
+	}
+
+	runBatch(searchClient, fetchClient, checks)
+}
+
+// serve answers lookups over HTTP on "server.addr" until interrupted
+func serve(api *server.Server) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              viper.GetString("server.addr"),
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		// jobs running in the background stop with the server
+		api.Close()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down the server: %v", err)
+		}
+	}()
+
+	log.Printf("Listening on %s", httpServer.Addr)
+
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// runBatch looks up every company in the input file and writes the results to the output file
+func runBatch(searchClient scraper.HTTPClient, fetchClient *scraper.FetchClient, checks scraper.EmailChecks) {
+	companies, err := scraper.ReadCompanies("companies-list/input.txt")
 	if err != nil {
 		log.Fatalf("Error reading input file: %v", err)
 	}
 	output := make(map[string]string)
 
-	// Create a new http.Client
-	client := &http.Client{}
+	format, err := scraper.GetOutputFormat()
+	if err != nil {
+		log.Fatalf("Error reading output format: %v", err)
+	}
 
 	// Create the output file once
 	fileName := "output/company_emails.txt"
+	if format == scraper.OutputJSONLines {
+		fileName = "output/company_emails.jsonl"
+	}
 
 	file, err := os.Create(fileName)
 	if err != nil {
@@ -28,30 +109,60 @@ func main() {
 	}
 	defer file.Close()
 
-	for i := range companyNames {
-		companyURL, err := scraper.GetSearchResults(
-			client,
-			companyNames[i],
+	ctx := context.Background()
+
+	for i := range companies {
+		companyURLs, err := scraper.GetSearchResultURLs(
+			ctx,
+			searchClient,
+			companies[i].Name,
 		)
 		if err != nil {
-			log.Printf("Error getting search results for %s: %v", companyNames[i], err)
-			output[companyNames[i]] = ""
+			log.Printf("Error getting search results for %s: %v", companies[i].Name, err)
+			output[companies[i].Name] = ""
+
+			if err := writeCompany(file, format, models.CompanyResult{Name: companies[i].Name}, err); err != nil {
+				log.Printf("Error writing to file for %s: %v", companies[i].Name, err)
+			}
 
 			continue
 		}
 
-		output[companyNames[i]] = companyURL
+		// Later search results are tried when a site yields no email; cookies are kept per company
+		company, err := scraper.ResolveCompanyContacts(
+			ctx, fetchClient.ForLookup(), checks, companyURLs, companies[i].Name, companies[i].Country,
+		)
+
+		for _, attempt := range company.Attempts {
+			if attempt.Err != nil {
+				log.Printf("Passed over %s for %s: %v", attempt.URL, companies[i].Name, attempt.Err)
+			}
+		}
+
+		output[companies[i].Name] = company.URL
 
-		email, err := scraper.GetCompanyEmail(companyURL, companyNames[i])
 		if err != nil {
-			log.Printf("Error fetching company email for %s: %v", companyNames[i], err)
-			continue
+			log.Printf("Error fetching company contacts for %s: %v", companies[i].Name, err)
 		}
 
-		err = scraper.WriteEmailsToFile(file, companyNames[i], email)
-		if err != nil {
-			log.Printf("Error writing to file for %s: %v", companyNames[i], err)
+		if err := writeCompany(file, format, company, err); err != nil {
+			log.Printf("Error writing to file for %s: %v", companies[i].Name, err)
 			continue
 		}
 	}
 }
+
+// writeCompany writes the result of a lookup in the output format. Text lines are only
+// written for companies with contacts; JSON lines are written for every company, with
+// the error of a failed lookup.
+func writeCompany(file *os.File, format scraper.OutputFormat, company models.CompanyResult, lookupErr error) error {
+	if format == scraper.OutputJSONLines {
+		return scraper.WriteCompanyRecord(file, company, lookupErr)
+	}
+
+	if lookupErr != nil {
+		return nil
+	}
+
+	return scraper.WriteEmailsToFile(file, company.Name, company.Email, company.Phones...)
+}
@@ -0,0 +1,27 @@
+package models
+
+// AddressSource describes where on a page a postal address was found.
+type AddressSource string
+
+const (
+	AddressFromSchema AddressSource = "schema" // schema.org PostalAddress in JSON-LD, microdata or RDFa
+	AddressFromHCard  AddressSource = "hcard"  // hCard "adr" or microformats2 "h-adr" markup
+	AddressFromText   AddressSource = "text"   // street, postal code and city written out in the page text
+)
+
+// PostalAddress is a company's street address split into its parts; parts that were not
+// found are empty.
+type PostalAddress struct {
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// AddressCandidate is a postal address extracted from a page together with where it was found.
+type AddressCandidate struct {
+	Address PostalAddress
+	Source  AddressSource
+	PageURL string // page the address was found on, set when crawling
+}
@@ -0,0 +1,24 @@
+package models
+
+// CompanyResult holds the contact details found for one company.
+type CompanyResult struct {
+	Name   string
+	URL    string        // site the details were taken from
+	Email  string        // address chosen by the selection policy; empty when none qualified
+	Emails []RankedEmail // every address found, best first
+	Phones []string      // E.164 numbers, schema.org ones first, then in the order found
+	Social SocialProfiles
+	// Address is the most complete postal address found; empty when none was
+	Address PostalAddress
+	// EmailPattern is how the company builds its people's addresses; empty when no named
+	// address was found
+	EmailPattern InferredPattern
+	// Attempts lists the search results tried for the company, in order
+	Attempts []SiteAttempt
+}
+
+// SiteAttempt records a search result tried for a company and why it was passed over.
+type SiteAttempt struct {
+	URL string
+	Err error // nil for the site the result was taken from
+}
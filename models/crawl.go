@@ -0,0 +1,29 @@
+package models
+
+// PageStatus is the outcome of visiting a single page during a crawl.
+type PageStatus string
+
+const (
+	PageFetched    PageStatus = "fetched"
+	PageFailed     PageStatus = "failed"
+	PageDisallowed PageStatus = "disallowed" // skipped because robots.txt forbids it
+)
+
+// PageResult records a page visited while crawling a company site.
+type PageResult struct {
+	URL    string
+	Depth  int
+	Status PageStatus
+	Err    error
+}
+
+// CrawlResult aggregates the pages visited on a company site and the emails, phone
+// numbers, social profile links, postal addresses and names of people found on them.
+type CrawlResult struct {
+	Pages     []PageResult
+	Emails    []EmailCandidate
+	Phones    []PhoneCandidate
+	Profiles  []SocialProfile
+	Addresses []AddressCandidate
+	Names     []PersonName
+}
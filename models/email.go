@@ -0,0 +1,71 @@
+package models
+
+// EmailSource describes where on a page an email candidate was found.
+type EmailSource string
+
+const (
+	SourceText       EmailSource = "text"       // visible text content
+	SourceMailto     EmailSource = "mailto"     // href of a mailto: link
+	SourceAttribute  EmailSource = "attribute"  // structured attribute such as meta content or data-email
+	SourceObfuscated EmailSource = "obfuscated" // text rewritten from "[at]"/"[dot]" or reversed (rtl) forms
+	SourceCloudflare EmailSource = "cloudflare" // Cloudflare email protection (data-cfemail or /cdn-cgi/l/email-protection)
+	SourceSchema     EmailSource = "schema"     // schema.org email in JSON-LD, microdata or RDFa
+)
+
+// EmailCandidate is an address extracted from a page together with where it was found.
+type EmailCandidate struct {
+	Address string // display form, with a Unicode domain
+	ASCII   string // same address with the domain in IDNA (punycode) form
+	Source  EmailSource
+	PageURL string // page the address was found on, set when crawling
+}
+
+// Deliverability tells whether an email's domain can receive mail, judged from its DNS records.
+type Deliverability string
+
+const (
+	DeliverabilityUnknown Deliverability = "unknown" // DNS lookup failed or timed out
+	DeliverableMX         Deliverability = "mx"      // domain publishes MX records
+	DeliverableA          Deliverability = "a"       // no MX, but an address record is the implicit mail host (RFC 5321 section 5.1)
+	Undeliverable         Deliverability = "none"    // domain does not exist, has no address records or publishes a null MX (RFC 7505)
+)
+
+// MailboxStatus is the answer of a domain's mail server to an RCPT TO probe for an address.
+type MailboxStatus string
+
+const (
+	MailboxUnknown  MailboxStatus = "unknown"   // server unreachable, greylisted (4xx) or the probe timed out
+	MailboxAccepted MailboxStatus = "accepted"  // server accepted the recipient
+	MailboxRejected MailboxStatus = "rejected"  // server refused the recipient (5xx)
+	MailboxCatchAll MailboxStatus = "catch-all" // server accepts any recipient, so acceptance proves nothing
+)
+
+// DomainRelation tells how the domain of an email relates to the company site.
+type DomainRelation string
+
+const (
+	DomainSame       DomainRelation = "same"        // the site's own host
+	DomainRelated    DomainRelation = "related"     // another host of the site's registrable domain, e.g. mail.acme.co.uk
+	DomainAlias      DomainRelation = "alias"       // the same name under another suffix, or a configured alias domain
+	DomainWebmail    DomainRelation = "webmail"     // a free mail provider such as gmail.com
+	DomainThirdParty DomainRelation = "third-party" // a hosting, site-builder or monitoring service, never the company's contact
+	DomainOther      DomainRelation = "other"       // unrelated, such as an agency or a partner
+)
+
+// RankedEmail is a distinct address found on a company site with its selection score.
+type RankedEmail struct {
+	Address    string
+	ASCII      string // Address with an IDNA (punycode) domain
+	Score      float64
+	Count      int           // number of times the address was found
+	Sources    []EmailSource // distinct sources, in the order first seen
+	SameDomain bool          // address domain belongs to the company site or one of its aliases
+	Position   int           // index of the first occurrence among the candidates
+	// Relation of the address domain to the company site
+	Relation DomainRelation
+
+	// Deliverability of the address domain; empty when it was not checked
+	Deliverability Deliverability
+	// Mailbox is the result of the opt-in SMTP probe; empty when it was not run
+	Mailbox MailboxStatus
+}
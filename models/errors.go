@@ -4,7 +4,6 @@ import "errors"
 
 var (
 	// static error variables for GetSearchResults
-	ErrInitConfig     = errors.New("error initializing configuration")
 	ErrAPIKeyNotSet   = errors.New("SERPAPI_KEY not set in config or environment")
 	ErrRequestFailed  = errors.New("failed to make request to SerpAPI")
 	ErrDecodeFailed   = errors.New("failed to decode SerpAPI response")
@@ -12,12 +11,38 @@ var (
 
 	// static error variables for GetCompanyEmail
 	ErrSkippingFacebookURL = errors.New("skipping Facebook URL")
+	ErrNoWebsiteLinked     = errors.New("no website linked from the Facebook page")
+	ErrNoUsableSite        = errors.New("no search result yielded an email")
 	ErrFetchFailed         = errors.New("failed to fetch the page")
 	ErrNonOKStatus         = errors.New("received non-OK HTTP status")
 	ErrReadFailed          = errors.New("failed to read response body")
 	ErrNoEmailFound        = errors.New("no email found on the page")
 	ErrInvalidCompanyURL   = errors.New("invalid company URL")
 	ErrWriteFileFailed     = errors.New("failed to write to file")
+	ErrUnknownPolicy       = errors.New("unknown email selection policy")
+	ErrUnknownOutputFormat = errors.New("unknown output format")
+	ErrInvalidSitemap      = errors.New("invalid sitemap")
+	ErrDisallowedByRobots  = errors.New("disallowed by robots.txt")
+	ErrInvalidProxy        = errors.New("invalid proxy configuration")
+
+	// static error variables for responses the crawler refuses to read
+	ErrResponseTooLarge       = errors.New("response body exceeds the size limit")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrUnreadablePDF          = errors.New("unreadable PDF document")
+
+	// static error variables for the HTTP API
+	ErrInvalidRequestBody = errors.New("invalid request body")
+	ErrMissingCompanyName = errors.New("company name is required")
+	ErrBatchTooLarge      = errors.New("too many companies in one job")
+	ErrJobNotFound        = errors.New("job not found")
+	ErrTooManyJobs        = errors.New("too many jobs in progress")
+	ErrServerBusy         = errors.New("no worker became free before the request ended")
+
+	// static error variables for extracted email addresses
+	ErrInvalidEmail     = errors.New("invalid email address")
+	ErrPlaceholderEmail = errors.New("placeholder email address")
+	ErrUndeliverable    = errors.New("email address cannot receive mail")
+	ErrThirdPartyEmail  = errors.New("email address belongs to a third-party service")
 
 	//
 	ErrBindingEnvVariable = errors.New("error binding environment variable")
@@ -0,0 +1,32 @@
+package models
+
+// EmailPattern is the way a company builds the local part of its people's addresses.
+type EmailPattern string
+
+const (
+	PatternFirstDotLast        EmailPattern = "first.last" // jane.doe
+	PatternFirstUnderscoreLast EmailPattern = "first_last" // jane_doe
+	PatternFirstHyphenLast     EmailPattern = "first-last" // jane-doe
+	PatternFirstLast           EmailPattern = "firstlast"  // janedoe
+	PatternInitialDotLast      EmailPattern = "f.last"     // j.doe
+	PatternInitialLast         EmailPattern = "flast"      // jdoe
+	PatternFirstInitial        EmailPattern = "firstl"     // janed
+	PatternFirst               EmailPattern = "first"      // jane
+	PatternLast                EmailPattern = "last"       // doe
+)
+
+// InferredPattern is the address pattern inferred from the named addresses on a company site.
+type InferredPattern struct {
+	Pattern EmailPattern
+	Domain  string // domain the pattern's addresses are on
+	// Confidence grows with the share of named addresses following the pattern and with
+	// their number, from 0 to 1
+	Confidence float64
+	Examples   []string // addresses the pattern was inferred from
+}
+
+// PersonName is a person named in the text of a company site, such as on a team page.
+type PersonName struct {
+	First string
+	Last  string
+}
@@ -0,0 +1,17 @@
+package models
+
+// PhoneSource describes where on a page a phone number was found.
+type PhoneSource string
+
+const (
+	PhoneFromTel    PhoneSource = "tel"    // href of a tel: link
+	PhoneFromText   PhoneSource = "text"   // visible text next to a "Tel"/"Phone" label, or in international format
+	PhoneFromSchema PhoneSource = "schema" // schema.org telephone in microdata or JSON-LD
+)
+
+// PhoneCandidate is a phone number extracted from a page, as it was written there.
+type PhoneCandidate struct {
+	Raw     string
+	Source  PhoneSource
+	PageURL string // page the number was found on, set when crawling
+}
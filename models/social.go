@@ -0,0 +1,39 @@
+package models
+
+// SocialNetwork names a site a company keeps a public profile on.
+type SocialNetwork string
+
+const (
+	LinkedIn  SocialNetwork = "linkedin"
+	Facebook  SocialNetwork = "facebook"
+	X         SocialNetwork = "x"
+	Instagram SocialNetwork = "instagram"
+	YouTube   SocialNetwork = "youtube"
+	GitHub    SocialNetwork = "github"
+)
+
+// SocialSource describes how a company site refers to a social profile.
+type SocialSource string
+
+const (
+	SocialFromLink   SocialSource = "link"   // an anchor on the page
+	SocialFromSchema SocialSource = "schema" // schema.org sameAs of the organization
+)
+
+// SocialProfile is a canonical profile URL linked from a company site.
+type SocialProfile struct {
+	Network SocialNetwork
+	URL     string
+	Source  SocialSource
+	PageURL string // page the link was found on, set when crawling
+}
+
+// SocialProfiles holds the profile chosen for each network; empty when none was linked.
+type SocialProfiles struct {
+	LinkedIn  string
+	Facebook  string
+	X         string
+	Instagram string
+	YouTube   string
+	GitHub    string
+}
@@ -0,0 +1,293 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// hCard (adr) and microformats2 (h-adr) class names, mapped to the schema.org
+// PostalAddress property they correspond to
+var hCardProperties = map[string]string{
+	"street-address":   schemaStreetAddress,
+	"p-street-address": schemaStreetAddress,
+	"locality":         schemaAddressLocality,
+	"p-locality":       schemaAddressLocality,
+	"region":           schemaAddressRegion,
+	"p-region":         schemaAddressRegion,
+	"postal-code":      schemaPostalCode,
+	"p-postal-code":    schemaPostalCode,
+	"country-name":     schemaAddressCountry,
+	"p-country-name":   schemaAddressCountry,
+}
+
+// Building blocks of the addresses recognized in page text; a street is on one line
+const (
+	// "Hauptstraße 12a", "Keizersgracht 1-3", "Via Roma 5"
+	namedStreetPattern = `(?:(?:\p{Lu}[\p{L}\p{M}.'’-]* ){0,3}[\p{L}\p{M}.'’-]*` +
+		`(?i:straße|strasse|str\.|weg|gasse|platz|allee|ring|damm|ufer|markt|chaussee|straat|laan|gracht|plein|vej|gade|gatan|vägen|veien)` +
+		`|(?i:via|viale|piazza|calle|avenida|rua|ulica|ul\.) (?:\p{Lu}[\p{L}\p{M}.'’-]* ?){1,3})` +
+		` ?\d{1,4} ?[a-zA-Z]?(?:[-/]\d{1,4})?`
+	// "12 rue de la Paix"
+	frenchStreetPattern = `\d{1,4}[a-zA-Z]?,? (?i:rue|avenue|boulevard|bd|place|chemin|allée|quai|impasse) [\p{L}\p{M} .'’-]{2,40}`
+	// "1600 Pennsylvania Avenue NW", "221B Baker Street"
+	englishStreetPattern = `\d{1,6}[A-Z]? (?:[\p{L}.'’-]+ ){1,4}` +
+		`(?:Street|St|Road|Rd|Avenue|Ave|Boulevard|Blvd|Lane|Ln|Drive|Dr|Way|Court|Ct|Place|Pl|Square|Sq|Parkway|Pkwy|Terrace|Highway|Hwy)` +
+		`\.?(?: [NS]?[EW]?\b)?(?:,? (?:Suite|Ste\.?|Unit|Floor|#) ?[\w-]+)?`
+	// parts of an address are written on separate lines or separated by commas
+	addressSeparator = `\s*[,\n]\s*`
+	// a city name runs to the end of its line
+	cityPattern = `(\p{Lu}[^\n,\d:|]{1,40})`
+)
+
+var (
+	// "Hauptstraße 1, 10115 Berlin", "12 rue de la Paix, F-75002 Paris"
+	europeanAddressRegex = regexp.MustCompile(`(` + namedStreetPattern + `|` + frenchStreetPattern + `)` +
+		addressSeparator + `(?:[A-Z]{1,2}-)?(\d{4,5})\s+` + cityPattern)
+
+	// "1600 Pennsylvania Avenue NW, Washington, DC 20500"
+	usAddressRegex = regexp.MustCompile(`(` + englishStreetPattern + `)` +
+		addressSeparator + `(\p{Lu}[^\n,\d]{1,40}?)\s*,\s*([A-Z]{2})\s+(\d{5}(?:-\d{4})?)\b`)
+
+	// "10 Downing Street, London SW1A 2AA"
+	ukAddressRegex = regexp.MustCompile(`(` + englishStreetPattern + `)` +
+		addressSeparator + `(\p{Lu}[^\n,\d]{1,40}?)\s*[,\n]?\s*([A-Z]{1,2}\d[A-Z\d]?\s?\d[A-Z]{2})\b`)
+
+	// a country written on the line after an address, or after a comma
+	trailingCountryRegex = regexp.MustCompile(`^` + addressSeparator + `(\p{L}[\p{L} ]{1,30}\p{L})`)
+)
+
+// Country names as written in addresses, in English and the local language, mapped to
+// their ISO 3166 code; codes of countries with a dialing plan are recognized as well
+var countryCodes = map[string]string{
+	"germany": "DE", "deutschland": "DE", "austria": "AT", "österreich": "AT",
+	"switzerland": "CH", "schweiz": "CH", "suisse": "CH", "svizzera": "CH",
+	"france": "FR", "belgium": "BE", "belgique": "BE", "belgië": "BE",
+	"netherlands": "NL", "the netherlands": "NL", "nederland": "NL", "luxembourg": "LU",
+	"italy": "IT", "italia": "IT", "spain": "ES", "españa": "ES", "portugal": "PT",
+	"united kingdom": "GB", "uk": "GB", "great britain": "GB", "england": "GB", "scotland": "GB",
+	"wales": "GB", "ireland": "IE", "denmark": "DK", "danmark": "DK", "sweden": "SE",
+	"sverige": "SE", "norway": "NO", "norge": "NO", "finland": "FI", "suomi": "FI",
+	"poland": "PL", "polska": "PL", "czech republic": "CZ", "czechia": "CZ", "česko": "CZ",
+	"united states": "US", "united states of america": "US", "usa": "US", "canada": "CA",
+	"mexico": "MX", "brazil": "BR", "brasil": "BR", "argentina": "AR",
+	"australia": "AU", "new zealand": "NZ", "japan": "JP", "china": "CN", "india": "IN",
+	"singapore": "SG", "south africa": "ZA", "nigeria": "NG", "kenya": "KE", "uganda": "UG",
+	"united arab emirates": "AE", "israel": "IL", "turkey": "TR", "türkiye": "TR",
+}
+
+// How much an address is trusted, by where it was found
+var addressSourceRanks = map[models.AddressSource]int{
+	models.AddressFromSchema: 2,
+	models.AddressFromHCard:  1,
+	models.AddressFromText:   0,
+}
+
+// SelectAddress picks the address with the most parts filled in, preferring structured
+// data over text on a tie, and otherwise the first one found; it is empty when no
+// address was found.
+func SelectAddress(candidates []models.AddressCandidate) models.PostalAddress {
+	var best *models.AddressCandidate
+
+	for i := range candidates {
+		candidate := &candidates[i]
+
+		switch {
+		case best == nil:
+			best = candidate
+		case addressParts(candidate.Address) != addressParts(best.Address):
+			if addressParts(candidate.Address) > addressParts(best.Address) {
+				best = candidate
+			}
+		case addressSourceRanks[candidate.Source] > addressSourceRanks[best.Source]:
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return models.PostalAddress{}
+	}
+
+	return best.Address
+}
+
+func addressParts(address models.PostalAddress) int {
+	parts := 0
+
+	for _, part := range []string{address.Street, address.City, address.Region, address.PostalCode, address.Country} {
+		if part != "" {
+			parts++
+		}
+	}
+
+	return parts
+}
+
+// normalizeAddress tidies the parts of an address: whitespace is collapsed, stray
+// separators trimmed, postal codes upper-cased and countries converted to ISO codes
+func normalizeAddress(address models.PostalAddress) models.PostalAddress {
+	clean := func(part string) string {
+		return strings.Trim(strings.Join(strings.Fields(part), " "), " ,;")
+	}
+
+	address.Street = clean(address.Street)
+	address.City = clean(address.City)
+	address.Region = clean(address.Region)
+	address.PostalCode = strings.ToUpper(clean(address.PostalCode))
+	address.Country = countryCode(clean(address.Country))
+
+	return address
+}
+
+// countryCode returns the ISO 3166 code of a country name, or the name unchanged when
+// it is not known
+func countryCode(country string) string {
+	if code, ok := countryCodes[strings.ToLower(country)]; ok {
+		return code
+	}
+
+	if _, ok := dialingPlans[strings.ToUpper(country)]; ok && len(country) == 2 {
+		return strings.ToUpper(country)
+	}
+
+	return country
+}
+
+// findAddresses returns the postal addresses written out in the text of a page, such as
+// the footer or imprint of a contact page
+func findAddresses(text string) []models.AddressCandidate {
+	var addresses []models.PostalAddress
+
+	for _, match := range usAddressRegex.FindAllStringSubmatchIndex(text, -1) {
+		addresses = append(addresses, withTrailingCountry(models.PostalAddress{
+			Street: text[match[2]:match[3]], City: text[match[4]:match[5]],
+			Region: text[match[6]:match[7]], PostalCode: text[match[8]:match[9]], Country: "US",
+		}, text[match[1]:]))
+	}
+
+	for _, match := range ukAddressRegex.FindAllStringSubmatchIndex(text, -1) {
+		addresses = append(addresses, models.PostalAddress{
+			Street: text[match[2]:match[3]], City: text[match[4]:match[5]],
+			PostalCode: text[match[6]:match[7]], Country: "GB",
+		})
+	}
+
+	for _, match := range europeanAddressRegex.FindAllStringSubmatchIndex(text, -1) {
+		addresses = append(addresses, withTrailingCountry(models.PostalAddress{
+			Street: text[match[2]:match[3]], PostalCode: text[match[4]:match[5]], City: text[match[6]:match[7]],
+		}, text[match[1]:]))
+	}
+
+	seen := make(map[models.PostalAddress]bool)
+
+	var candidates []models.AddressCandidate
+
+	for _, address := range addresses {
+		address = normalizeAddress(address)
+		if seen[address] {
+			continue
+		}
+
+		seen[address] = true
+		candidates = append(candidates, models.AddressCandidate{Address: address, Source: models.AddressFromText})
+	}
+
+	return candidates
+}
+
+// withTrailingCountry sets the country of an address from the text following it, when
+// that text is a known country name
+func withTrailingCountry(address models.PostalAddress, after string) models.PostalAddress {
+	match := trailingCountryRegex.FindStringSubmatch(after)
+	if match == nil {
+		return address
+	}
+
+	if code, ok := countryCodes[strings.ToLower(match[1])]; ok {
+		address.Country = code
+	}
+
+	return address
+}
+
+// startHCardTag reads hCard and microformats2 address classes: an "adr" or "h-adr"
+// element starts an address, and a part's class makes the next text its value
+func (s *pageScanner) startHCardTag(token html.Token) {
+	for _, attr := range token.Attr {
+		if !strings.EqualFold(attr.Key, "class") {
+			continue
+		}
+
+		for _, class := range strings.Fields(strings.ToLower(attr.Val)) {
+			if property, ok := hCardProperties[class]; ok {
+				s.hCardProp = property
+			} else if class == "adr" || class == "h-adr" {
+				s.startAddress(models.AddressFromHCard)
+			}
+		}
+	}
+}
+
+// hCardText takes text as the value of a pending hCard address part
+func (s *pageScanner) hCardText(text string) {
+	if s.hCardProp == "" || strings.TrimSpace(text) == "" {
+		return
+	}
+
+	s.setAddressProperty(s.hCardProp, text, models.AddressFromHCard)
+	s.hCardProp = ""
+}
+
+// addressField returns the part of an address a schema.org PostalAddress property sets
+func addressField(address *models.PostalAddress, property string) *string {
+	switch property {
+	case schemaStreetAddress:
+		return &address.Street
+	case schemaAddressLocality:
+		return &address.City
+	case schemaAddressRegion:
+		return &address.Region
+	case schemaPostalCode:
+		return &address.PostalCode
+	case schemaAddressCountry:
+		return &address.Country
+	}
+
+	return nil
+}
+
+// setAddressProperty fills in a part of the address being read. A part that is already
+// set, or markup of another kind, starts the next address, for pages that list several
+// offices without wrapping each one.
+func (s *pageScanner) setAddressProperty(property, value string, source models.AddressSource) {
+	if addressField(&models.PostalAddress{}, property) == nil {
+		return
+	}
+
+	if s.address == nil || s.addressSource != source || *addressField(s.address, property) != "" {
+		s.startAddress(source)
+	}
+
+	*addressField(s.address, property) = value
+}
+
+func (s *pageScanner) startAddress(source models.AddressSource) {
+	s.endAddress()
+	s.address = &models.PostalAddress{}
+	s.addressSource = source
+}
+
+func (s *pageScanner) endAddress() {
+	if s.address == nil {
+		return
+	}
+
+	if address := normalizeAddress(*s.address); address != (models.PostalAddress{}) {
+		s.addresses = append(s.addresses, models.AddressCandidate{Address: address, Source: s.addressSource})
+	}
+
+	s.address = nil
+}
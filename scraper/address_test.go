@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestSelectAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []models.AddressCandidate
+		expected   models.PostalAddress
+	}{
+		{name: "No addresses"},
+		{
+			name: "Most complete address wins",
+			candidates: []models.AddressCandidate{
+				{Address: models.PostalAddress{City: "Berlin"}},
+				{Address: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin", PostalCode: "10115"}},
+			},
+			expected: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin", PostalCode: "10115"},
+		},
+		{
+			name: "First address wins a tie",
+			candidates: []models.AddressCandidate{
+				{Address: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"}},
+				{Address: models.PostalAddress{Street: "Marienplatz 2", City: "München"}},
+			},
+			expected: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"},
+		},
+		{
+			name: "Structured data wins a tie over text",
+			candidates: []models.AddressCandidate{
+				{Address: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"}, Source: models.AddressFromText},
+				{Address: models.PostalAddress{Street: "Marienplatz 2", City: "München"}, Source: models.AddressFromHCard},
+			},
+			expected: models.PostalAddress{Street: "Marienplatz 2", City: "München"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SelectAddress(tc.candidates))
+		})
+	}
+}
+
+func TestFindAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []models.PostalAddress
+	}{
+		{
+			name:     "German address on separate lines",
+			text:     "Acme GmbH\nHauptstraße 12a\n10115 Berlin\nDeutschland\nTel: 030 1234567",
+			expected: []models.PostalAddress{{Street: "Hauptstraße 12a", City: "Berlin", PostalCode: "10115", Country: "DE"}},
+		},
+		{
+			name:     "Abbreviated street and a city of several words",
+			text:     "Visit us: Kaiserstr. 5, 60311 Frankfurt am Main",
+			expected: []models.PostalAddress{{Street: "Kaiserstr. 5", City: "Frankfurt am Main", PostalCode: "60311"}},
+		},
+		{
+			name:     "French address with a country prefix on the postal code",
+			text:     "12 rue de la Paix, F-75002 Paris, France",
+			expected: []models.PostalAddress{{Street: "12 rue de la Paix", City: "Paris", PostalCode: "75002", Country: "FR"}},
+		},
+		{
+			name: "US address with a suite",
+			text: "1600 Pennsylvania Avenue NW, Suite 200\nWashington, DC 20500",
+			expected: []models.PostalAddress{
+				{Street: "1600 Pennsylvania Avenue NW, Suite 200", City: "Washington", Region: "DC", PostalCode: "20500", Country: "US"},
+			},
+		},
+		{
+			name:     "UK address",
+			text:     "Acme Ltd, 221B Baker Street, London NW1 6XE",
+			expected: []models.PostalAddress{{Street: "221B Baker Street", City: "London", PostalCode: "NW1 6XE", Country: "GB"}},
+		},
+		{
+			name: "Numbers that are not addresses",
+			text: "Order 12345 shipped on 2024-01-15\nCall 030 1234567\nSince 1998, 25000 customers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var addresses []models.PostalAddress
+
+			for _, candidate := range findAddresses(tc.text) {
+				assert.Equal(t, models.AddressFromText, candidate.Source)
+				addresses = append(addresses, candidate.Address)
+			}
+
+			assert.Equal(t, tc.expected, addresses)
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  models.PostalAddress
+		expected models.PostalAddress
+	}{
+		{
+			name:     "Whitespace and separators are trimmed",
+			address:  models.PostalAddress{Street: " Hauptstr.\n  1, ", City: "Berlin ;"},
+			expected: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"},
+		},
+		{
+			name:     "Postal codes are upper-cased",
+			address:  models.PostalAddress{PostalCode: "sw1a 2aa"},
+			expected: models.PostalAddress{PostalCode: "SW1A 2AA"},
+		},
+		{name: "Country names become codes", address: models.PostalAddress{Country: "Österreich"}, expected: models.PostalAddress{Country: "AT"}},
+		{name: "Country codes are upper-cased", address: models.PostalAddress{Country: "de"}, expected: models.PostalAddress{Country: "DE"}},
+		{name: "Unknown countries are kept", address: models.PostalAddress{Country: "Atlantis"}, expected: models.PostalAddress{Country: "Atlantis"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeAddress(tc.address))
+		})
+	}
+}
+
+func TestPageScannerHCard(t *testing.T) {
+	document := `<div class="vcard">
+  <span class="fn org">Acme Inc.</span>
+  <div class="adr">
+    <div class="street-address">500 Market Street</div>
+    <span class="locality">San Francisco</span>, <abbr class="region">CA</abbr>
+    <span class="postal-code">94105</span> <div class="country-name">USA</div>
+  </div>
+</div>
+<p class="h-adr"><span class="p-street-address">Hauptstr. 1</span> <span class="p-locality">Berlin</span></p>`
+
+	scanner := &pageScanner{}
+	assert.NoError(t, scanner.scan(strings.NewReader(document)))
+
+	assert.Equal(t, []models.AddressCandidate{
+		{
+			Address: models.PostalAddress{Street: "500 Market Street", City: "San Francisco", Region: "CA", PostalCode: "94105", Country: "US"},
+			Source:  models.AddressFromHCard,
+		},
+		{Address: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"}, Source: models.AddressFromHCard},
+	}, scanner.addresses)
+}
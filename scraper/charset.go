@@ -0,0 +1,29 @@
+package scraper
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// decodeToUTF8 transcodes a text or HTML body to UTF-8. The encoding is taken from a
+// byte order mark, the Content-Type charset or a <meta> declaration, in that order.
+// A body that is valid UTF-8 is kept as is unless a BOM or header says otherwise,
+// since undeclared pages are far more often UTF-8 than the windows-1252 fallback.
+func decodeToUTF8(body io.Reader, contentType string) (io.Reader, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, _, certain := charset.DetermineEncoding(raw, contentType)
+	if enc == encoding.Nop || (!certain && utf8.Valid(raw)) {
+		return bytes.NewReader(raw), nil
+	}
+
+	return transform.NewReader(bytes.NewReader(raw), enc.NewDecoder()), nil
+}
@@ -1,11 +1,12 @@
 package scraper
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -161,6 +162,24 @@ func TestReadCompanyNames(t *testing.T) {
 	}
 }
 
+func TestReadCompanies(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "input.txt")
+	err := os.WriteFile(filePath, []byte("Acme GmbH\tde\nGlobex\n Initech \t \n"), 0o600)
+	assert.NoError(t, err)
+
+	companies, err := ReadCompanies(filePath)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []CompanyInput{
+		{Name: "Acme GmbH", Country: "de"},
+		{Name: "Globex"},
+		{Name: "Initech"},
+	}, companies)
+
+	_, err = ReadCompanies(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
 func TestGetSearchResults(t *testing.T) {
 	type dependencies struct {
 		apiKey string
@@ -281,7 +300,7 @@ func TestGetSearchResults(t *testing.T) {
 			viper.Set("serpapi.api_key", tt.dependencies.apiKey)
 
 			// Call GetSearchResults with args
-			result, err := GetSearchResults(tt.client, tt.args.companyName)
+			result, err := GetSearchResults(context.Background(), tt.client, tt.args.companyName)
 
 			// Assert the expected result and error
 			assert.Equal(t, tt.expected.result, result)
@@ -295,11 +314,72 @@ func TestGetSearchResults(t *testing.T) {
 	}
 }
 
+func TestGetSearchResultURLs(t *testing.T) {
+	type expected struct {
+		urls []string
+		num  string
+		err  error
+	}
+
+	tests := []struct {
+		name          string
+		response      string
+		searchResults int // configured "scraper.search_results", 0 when unset
+		expected      expected
+	}{
+		{
+			name: "success/Results in order without duplicates",
+			response: `{"organic": [{"link": "https://www.facebook.com/acme"}, {"link": "https://acme.test/"},
+				{"link": ""}, {"link": "https://www.facebook.com/acme"}, {"link": "https://acme.test/about"}]}`,
+			expected: expected{urls: []string{"https://www.facebook.com/acme", "https://acme.test/", "https://acme.test/about"}, num: "10"},
+		},
+		{
+			name:          "success/Configured number of results",
+			response:      `{"organic": [{"link": "https://acme.test/"}]}`,
+			searchResults: 3,
+			expected:      expected{urls: []string{"https://acme.test/"}, num: "3"},
+		},
+		{
+			name:     "error/No search results",
+			response: `{"organic": []}`,
+			expected: expected{err: models.ErrNoResultsFound, num: "10"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("serpapi.api_key", "valid_api_key")
+
+			if tc.searchResults > 0 {
+				viper.Set("scraper.search_results", tc.searchResults)
+				defer viper.Set("scraper.search_results", nil)
+			}
+
+			var query string
+
+			client := &MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					query = req.URL.RawQuery
+
+					return mockHTTPResponse(http.StatusOK, tc.response), nil
+				},
+			}
+
+			urls, err := GetSearchResultURLs(context.Background(), client, "Acme")
+
+			assert.Equal(t, tc.expected.urls, urls)
+			assert.ErrorIs(t, err, tc.expected.err)
+			assert.Contains(t, query, "num="+tc.expected.num+"&")
+		})
+	}
+}
+
 func TestGetCompanyEmail(t *testing.T) {
 	// Define structs for dependencies, args, and expected results
 	type dependencies struct {
 		mockResponse string
 		statusCode   int
+		err          error
 	}
 
 	type args struct {
@@ -308,8 +388,8 @@ func TestGetCompanyEmail(t *testing.T) {
 	}
 
 	type expected struct {
-		wantEmail   string
-		expectError bool
+		wantEmail string
+		err       error
 	}
 
 	// Define the test cases
@@ -322,17 +402,17 @@ func TestGetCompanyEmail(t *testing.T) {
 		{
 			name: "Valid email found",
 			dependencies: dependencies{
-				mockResponse: `<html><body>Contact us at test@example.com</body></html>`,
+				mockResponse: `<html><body>Contact us at test@acme.test</body></html>`,
 				statusCode:   http.StatusOK,
 			},
 			args: args{
-				companyURL:  "/test-company",
+				companyURL:  "https://acme.test/test-company",
 				companyName: "Test Company",
 			},
 
 			expected: expected{
-				wantEmail:   "test@example.com",
-				expectError: false,
+				wantEmail: "test@acme.test",
+				err:       nil,
 			},
 		},
 		{
@@ -342,13 +422,29 @@ func TestGetCompanyEmail(t *testing.T) {
 				statusCode:   http.StatusOK,
 			},
 			args: args{
-				companyURL:  "/no-email-company",
+				companyURL:  "https://acme.test/no-email-company",
 				companyName: "No Email Company",
 			},
 
 			expected: expected{
-				wantEmail:   "",
-				expectError: true,
+				wantEmail: "",
+				err:       models.ErrNoEmailFound,
+			},
+		},
+		{
+			name: "Placeholder emails are ignored",
+			dependencies: dependencies{
+				mockResponse: `<html><body><input placeholder="your@email.com"> Write to user@example.com</body></html>`,
+				statusCode:   http.StatusOK,
+			},
+			args: args{
+				companyURL:  "https://acme.test/placeholder-company",
+				companyName: "Placeholder Company",
+			},
+
+			expected: expected{
+				wantEmail: "",
+				err:       models.ErrNoEmailFound,
 			},
 		},
 		{
@@ -363,8 +459,8 @@ func TestGetCompanyEmail(t *testing.T) {
 			},
 
 			expected: expected{
-				wantEmail:   "",
-				expectError: true,
+				wantEmail: "",
+				err:       models.ErrSkippingFacebookURL,
 			},
 		},
 		{
@@ -374,13 +470,44 @@ func TestGetCompanyEmail(t *testing.T) {
 				statusCode:   http.StatusInternalServerError,
 			},
 			args: args{
-				companyURL:  "/server-error",
+				companyURL:  "https://acme.test/server-error",
 				companyName: "Server Error Company",
 			},
 
 			expected: expected{
-				wantEmail:   "",
-				expectError: true,
+				wantEmail: "",
+				err:       models.ErrNonOKStatus,
+			},
+		},
+		{
+			name: "Invalid URL",
+			dependencies: dependencies{
+				mockResponse: "",
+				statusCode:   http.StatusOK,
+			},
+			args: args{
+				companyURL:  "acme.test/contact",
+				companyName: "Relative URL Company",
+			},
+
+			expected: expected{
+				wantEmail: "",
+				err:       models.ErrInvalidCompanyURL,
+			},
+		},
+		{
+			name: "Request failure",
+			dependencies: dependencies{
+				err: models.ErrNetwork,
+			},
+			args: args{
+				companyURL:  "https://acme.test/unreachable",
+				companyName: "Unreachable Company",
+			},
+
+			expected: expected{
+				wantEmail: "",
+				err:       models.ErrFetchFailed,
 			},
 		},
 	}
@@ -388,29 +515,149 @@ func TestGetCompanyEmail(t *testing.T) {
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			// Create a mock server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-				w.WriteHeader(tc.dependencies.statusCode)
+			// Serve the page from a mock client; robots.txt and sitemaps do not exist
+			client := &MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					if tc.dependencies.err != nil {
+						return nil, tc.dependencies.err
+					}
+
+					if req.URL.Path == "/robots.txt" || req.URL.Path == "/sitemap.xml" {
+						return mockHTTPResponse(http.StatusNotFound, ""), nil
+					}
+
+					return mockHTTPResponse(tc.dependencies.statusCode, tc.dependencies.mockResponse), nil
+				},
+			}
 
-				_, err := w.Write([]byte(tc.dependencies.mockResponse))
-				if err != nil {
-					t.Error("failed to write mock response")
-				}
-			}))
-			defer server.Close()
+			// Call the function under test
+			email, err := GetCompanyEmail(context.Background(), client, EmailChecks{}, tc.args.companyURL, tc.args.companyName)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+			} else {
+				assert.NoError(t, err)
+			}
 
-			// Use the mock server's URL for the test
-			companyURL := server.URL + tc.args.companyURL
+			assert.Equal(t, tc.expected.wantEmail, email)
+		})
+	}
+}
 
-			// Call the function under test
-			email, err := GetCompanyEmail(companyURL, tc.args.companyName)
-			if (err != nil) != tc.expected.expectError {
-				t.Fatalf("expected error: %v, got: %v", tc.expected.expectError, err)
+func TestGetCompanyContacts(t *testing.T) {
+	type dependencies struct {
+		mockResponse string
+	}
+
+	type args struct {
+		companyURL string
+		country    string
+	}
+
+	type expected struct {
+		email   string
+		phones  []string
+		social  models.SocialProfiles
+		address models.PostalAddress
+		pattern models.InferredPattern
+		err     error
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		args         args
+		expected     expected
+	}{
+		{
+			name: "success/Pattern of the team's addresses",
+			dependencies: dependencies{
+				mockResponse: `<p>info@acme.test</p><h2>Our team</h2>
+					<p>Jane Doe, CEO: <a href="mailto:jdoe@acme.test">jdoe@acme.test</a></p>
+					<p>John Smith, CTO: <a href="mailto:jsmith@acme.test">jsmith@acme.test</a></p>`,
+			},
+			args: args{companyURL: "https://acme.test/"},
+			expected: expected{
+				email: "info@acme.test",
+				pattern: models.InferredPattern{
+					Pattern: models.PatternInitialLast, Domain: "acme.test", Confidence: 0.75,
+					Examples: []string{"jdoe@acme.test", "jsmith@acme.test"},
+				},
+			},
+		},
+		{
+			name: "success/Email and phones",
+			dependencies: dependencies{
+				mockResponse: `<p>info@acme.de</p><p>Tel: 030 1234567</p><a href="tel:+4930 7654321">Call</a>
+					<footer><a href="https://www.linkedin.com/company/acme/">LinkedIn</a>
+					<p itemscope itemtype="https://schema.org/PostalAddress"><span itemprop="streetAddress">Hauptstr. 1</span>
+					<span itemprop="addressLocality">Berlin</span></p></footer>`,
+			},
+			args: args{companyURL: "https://acme.de/"},
+			expected: expected{
+				email:   "info@acme.de",
+				phones:  []string{"+49301234567", "+49307654321"},
+				social:  models.SocialProfiles{LinkedIn: "https://www.linkedin.com/company/acme"},
+				address: models.PostalAddress{Street: "Hauptstr. 1", City: "Berlin"},
+			},
+		},
+		{
+			name: "success/Phones without an email",
+			dependencies: dependencies{
+				mockResponse: `<p>Call +44 20 7946 0958</p>`,
+			},
+			args: args{companyURL: "https://acme.test/"},
+			expected: expected{
+				phones: []string{"+442079460958"},
+			},
+		},
+		{
+			name: "success/National numbers of the given country",
+			dependencies: dependencies{
+				mockResponse: `<p>info@acme.com</p><p>Tel: 01 234 5678</p>`,
+			},
+			args: args{companyURL: "https://acme.com/", country: "IE"},
+			expected: expected{
+				email:  "info@acme.com",
+				phones: []string{"+35312345678"},
+			},
+		},
+		{
+			name: "error/No email or phone",
+			dependencies: dependencies{
+				mockResponse: `<p>Welcome</p>`,
+			},
+			args: args{companyURL: "https://acme.test/"},
+			expected: expected{
+				err: models.ErrNoEmailFound,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != "/" {
+						return mockHTTPResponse(http.StatusNotFound, ""), nil
+					}
+
+					return mockHTTPResponse(http.StatusOK, tc.dependencies.mockResponse), nil
+				},
 			}
 
-			if email != tc.expected.wantEmail {
-				t.Errorf("expected email: %s, got: %s", tc.expected.wantEmail, email)
+			company, err := GetCompanyContacts(context.Background(), client, EmailChecks{}, tc.args.companyURL, "Acme", tc.args.country)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+			} else {
+				assert.NoError(t, err)
 			}
+
+			assert.Equal(t, "Acme", company.Name)
+			assert.Equal(t, tc.expected.email, company.Email)
+			assert.Equal(t, tc.expected.phones, company.Phones)
+			assert.Equal(t, tc.expected.social, company.Social)
+			assert.Equal(t, tc.expected.address, company.Address)
+			assert.Equal(t, tc.expected.pattern, company.EmailPattern)
 		})
 	}
 }
@@ -419,6 +666,7 @@ func TestWriteEmailsToFile(t *testing.T) {
 	tests := map[string]struct {
 		companyName string
 		email       string
+		phones      []string
 		wantOutput  string
 	}{
 		"Test Company": {
@@ -436,6 +684,12 @@ func TestWriteEmailsToFile(t *testing.T) {
 			email:       "",
 			wantOutput:  "Empty Email : \n",
 		},
+		"With Phones": {
+			companyName: "Phone Company",
+			email:       "info@acme.test",
+			phones:      []string{"+49301234567", "+14155550100"},
+			wantOutput:  "Phone Company : info@acme.test : +49301234567, +14155550100\n",
+		},
 	}
 
 	for name, tc := range tests {
@@ -448,7 +702,7 @@ func TestWriteEmailsToFile(t *testing.T) {
 			defer os.Remove(tmpFile.Name())
 
 			// Call WriteEmailsToFile
-			err = WriteEmailsToFile(tmpFile, tc.companyName, tc.email)
+			err = WriteEmailsToFile(tmpFile, tc.companyName, tc.email, tc.phones...)
 			if err != nil {
 				t.Fatalf("WriteEmailsToFile() error = %v", err)
 			}
@@ -5,18 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
 	"github.com/spf13/viper"
 
-	"github.com/Businge931/company-email-scraper/configs"
 	"github.com/Businge931/company-email-scraper/models"
 )
 
@@ -52,34 +49,89 @@ func ReadCompanyNames(filepath string) ([]string, error) {
 	return companyNames, nil
 }
 
-func GetSearchResults(client HTTPClient, companyName string) (string, error) {
-	if err := configs.InitConfig(); err != nil {
-		return "", fmt.Errorf("%w: %w", models.ErrInitConfig, err)
+// CompanyInput is a company to look up and, optionally, the ISO code of the country its
+// national phone numbers belong to.
+type CompanyInput struct {
+	Name    string
+	Country string
+}
+
+// ReadCompanies reads one company per line of the input file: its name, optionally
+// followed by a tab and its country, as in "Acme GmbH\tDE".
+func ReadCompanies(filepath string) ([]CompanyInput, error) {
+	lines, err := ReadCompanyNames(filepath)
+	if err != nil {
+		return nil, err
 	}
 
-	apiKey, err := getAPIKey()
+	companies := make([]CompanyInput, len(lines))
+
+	for i, line := range lines {
+		name, country, _ := strings.Cut(line, "\t")
+		companies[i] = CompanyInput{Name: strings.TrimSpace(name), Country: strings.TrimSpace(country)}
+	}
+
+	return companies, nil
+}
+
+// Organic results requested per company when "scraper.search_results" is not set, enough
+// to pass over Facebook pages and sites already tried on the way to "scraper.max_sites"
+const defaultSearchResults = 10
+
+func getSearchResults() int {
+	if viper.IsSet("scraper.search_results") {
+		return max(viper.GetInt("scraper.search_results"), 1)
+	}
+
+	return defaultSearchResults
+}
+
+// GetSearchResults returns the link of the first organic search result for a company.
+// The caller initializes the configuration once with configs.InitConfig beforehand, as
+// the API key is read from it.
+func GetSearchResults(ctx context.Context, client HTTPClient, companyName string) (string, error) {
+	serpResponse, err := search(ctx, client, companyName, 1)
 	if err != nil {
 		return "", err
 	}
 
-	searchURL, err := buildSearchURL(companyName, apiKey)
+	return extractFirstResultURL(serpResponse, companyName)
+}
+
+// GetSearchResultURLs returns the links of the first "scraper.search_results" organic
+// search results for a company, best first and without duplicates. Like GetSearchResults,
+// it expects configs.InitConfig to have been called.
+func GetSearchResultURLs(ctx context.Context, client HTTPClient, companyName string) ([]string, error) {
+	serpResponse, err := search(ctx, client, companyName, getSearchResults())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	resp, err := makeHTTPRequest(client, searchURL)
+	return extractResultURLs(serpResponse, companyName)
+}
+
+// search queries the search API for count results with the key from the configuration.
+// The configuration is only read here, so lookups may run concurrently once it is
+// initialized.
+func search(ctx context.Context, client HTTPClient, companyName string, count int) (SerpAPIResponse, error) {
+	apiKey, err := getAPIKey()
 	if err != nil {
-		return "", err
+		return SerpAPIResponse{}, err
 	}
 
-	defer resp.Body.Close()
+	searchURL, err := buildSearchURL(companyName, apiKey, count)
+	if err != nil {
+		return SerpAPIResponse{}, err
+	}
 
-	serpResponse, err := decodeResponse(resp)
+	resp, err := makeHTTPRequest(ctx, client, searchURL)
 	if err != nil {
-		return "", err
+		return SerpAPIResponse{}, err
 	}
 
-	return extractFirstResultURL(serpResponse, companyName)
+	defer resp.Body.Close()
+
+	return decodeResponse(resp)
 }
 
 func getAPIKey() (string, error) {
@@ -91,7 +143,7 @@ func getAPIKey() (string, error) {
 	return apiKey, nil
 }
 
-func buildSearchURL(companyName, apiKey string) (string, error) {
+func buildSearchURL(companyName, apiKey string, count int) (string, error) {
 	baseURL := "https://google.serper.dev/search"
 	params := struct {
 		Query  string `url:"q"`
@@ -101,7 +153,7 @@ func buildSearchURL(companyName, apiKey string) (string, error) {
 	}{
 		Query:  companyName,
 		APIKey: apiKey,
-		Num:    1,
+		Num:    count,
 		Engine: "google",
 	}
 
@@ -113,8 +165,8 @@ func buildSearchURL(companyName, apiKey string) (string, error) {
 	return fmt.Sprintf("%s?%s", baseURL, queryParams.Encode()), nil
 }
 
-func makeHTTPRequest(client HTTPClient, url string) (*http.Response, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func makeHTTPRequest(ctx context.Context, client HTTPClient, url string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -153,64 +205,184 @@ func extractFirstResultURL(serpResponse SerpAPIResponse, companyName string) (st
 	return serpResponse.Organic[0].Link, nil
 }
 
-func GetCompanyEmail(companyURL, companyName string) (string, error) {
-	// skip Facebook URLs
-	if strings.Contains(companyURL, "facebook.com") {
-		return "", fmt.Errorf("%w: %s", models.ErrSkippingFacebookURL, companyURL)
+func extractResultURLs(serpResponse SerpAPIResponse, companyName string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	var urls []string
+
+	for _, result := range serpResponse.Organic {
+		if result.Link != "" && !seen[result.Link] {
+			seen[result.Link] = true
+			urls = append(urls, result.Link)
+		}
 	}
 
-	// Validate the URL
-	parsedURL, err := url.ParseRequestURI(companyURL)
-	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return "", fmt.Errorf("%w: %s", models.ErrInvalidCompanyURL, companyURL)
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%w: %s", models.ErrNoResultsFound, companyName)
 	}
 
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	return urls, nil
+}
 
-	// Create a new HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, companyURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", models.ErrFetchFailed, err)
+// EmailChecks are the optional checks run on the emails found on a company site;
+// nil checks are skipped.
+type EmailChecks struct {
+	Deliverability *DeliverabilityChecker
+	Mailbox        *SMTPVerifier
+	// ExcludeUndeliverable keeps emails the checks find cannot receive mail from being
+	// selected; otherwise their results are only reported
+	ExcludeUndeliverable bool
+}
+
+// NewEmailChecksFromConfig builds the checks enabled in the configuration
+func NewEmailChecksFromConfig() EmailChecks {
+	return EmailChecks{
+		Deliverability:       NewDeliverabilityCheckerFromConfig(),
+		Mailbox:              NewSMTPVerifierFromConfig(),
+		ExcludeUndeliverable: viper.GetBool("deliverability.exclude_undeliverable"),
+	}
+}
+
+// run checks the domains first, so mail servers are only probed for deliverable ones
+func (c EmailChecks) run(ctx context.Context, ranked []models.RankedEmail) {
+	if c.Deliverability != nil {
+		c.Deliverability.CheckEmails(ctx, ranked)
+	}
+
+	if c.Mailbox != nil {
+		c.Mailbox.VerifyEmails(ctx, ranked)
 	}
+}
 
-	// Make the HTTP request
-	resp, err := http.DefaultClient.Do(req)
+// GetCompanyEmail returns the company's contact email chosen by the configured selection
+// policy. Emails the checks find cannot receive mail are passed over when the checks
+// exclude them.
+func GetCompanyEmail(ctx context.Context, client HTTPClient, checks EmailChecks, companyURL, companyName string) (string, error) {
+	policy, err := getSelectionPolicy()
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", models.ErrFetchFailed, err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	// Check for non-OK status
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("%w: %s", models.ErrNonOKStatus, resp.Status)
+	ranked, err := GetCompanyEmails(ctx, client, checks, companyURL, companyName)
+	if err != nil {
+		return "", err
 	}
 
-	// Read the body of the response
-	body, err := io.ReadAll(resp.Body)
+	email, err := SelectEmail(ranked, policy, checks.ExcludeUndeliverable)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", models.ErrReadFailed, err)
+		return "", fmt.Errorf("%w: %s", err, companyName)
 	}
 
-	// Convert the body to a string for easier searching
-	bodyStr := string(body)
+	return email.Address, nil
+}
 
-	// Regular expression to find emails
-	emailRegex := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
-	emails := emailRegex.FindAllString(bodyStr, -1)
+// GetCompanyEmails crawls the company page and the contact pages it links to and returns
+// every email found, ranked from most to least likely to be the company's contact address.
+// The results of the checks are filled in on each email.
+func GetCompanyEmails(
+	ctx context.Context, client HTTPClient, checks EmailChecks, companyURL, companyName string,
+) ([]models.RankedEmail, error) {
+	result, siteURL, err := crawlCompany(ctx, client, companyURL)
+	if err != nil {
+		return nil, err
+	}
 
 	// If no emails are found, return an error
-	if len(emails) == 0 {
-		return "", fmt.Errorf("%w: %s", models.ErrNoEmailFound, companyName)
+	if len(result.Emails) == 0 {
+		return nil, fmt.Errorf("%w: %s", models.ErrNoEmailFound, companyName)
 	}
 
-	// Return the first email found
-	return emails[0], nil
+	ranked := RankEmails(result.Emails, siteURL)
+	checks.run(ctx, ranked)
+
+	return ranked, nil
 }
 
-func WriteEmailsToFile(file *os.File, companyName, email string) error {
-	_, err := file.WriteString(fmt.Sprintf("%s : %s\n", companyName, email))
+// GetCompanyContacts crawls a company site once and returns the email chosen by the
+// selection policy together with every ranked email, phone number, social profile and
+// postal address found, and the pattern of the company's named addresses. When phone
+// numbers were found, an email that fails selection only leaves Email empty; the call
+// fails when there is neither an email nor a phone number. National phone numbers are
+// read as numbers of country, an ISO code that may be empty to fall back to the
+// configured default or the site's country-code TLD.
+func GetCompanyContacts(
+	ctx context.Context, client HTTPClient, checks EmailChecks, companyURL, companyName, country string,
+) (models.CompanyResult, error) {
+	company := models.CompanyResult{Name: companyName, URL: companyURL}
+
+	policy, err := getSelectionPolicy()
+	if err != nil {
+		return company, err
+	}
+
+	result, siteURL, err := crawlCompany(ctx, client, companyURL)
+	if err != nil {
+		return company, err
+	}
+
+	company.URL = siteURL
+	company.Phones = NormalizePhones(result.Phones, phoneCountry(siteURL, country))
+	company.Social = SelectSocialProfiles(result.Profiles)
+	company.Address = SelectAddress(result.Addresses)
+
+	if len(result.Emails) > 0 {
+		company.Emails = RankEmails(result.Emails, siteURL)
+		company.EmailPattern = InferEmailPattern(company.Emails, result.Names)
+		checks.run(ctx, company.Emails)
+
+		email, err := SelectEmail(company.Emails, policy, checks.ExcludeUndeliverable)
+
+		switch {
+		case err == nil:
+			company.Email = email.Address
+		case len(company.Phones) == 0:
+			return company, fmt.Errorf("%w: %s", err, companyName)
+		}
+	}
+
+	if company.Email == "" && len(company.Phones) == 0 {
+		return company, fmt.Errorf("%w: %s", models.ErrNoEmailFound, companyName)
+	}
+
+	return company, nil
+}
+
+// crawlCompany validates a company URL and crawls the page and the contact pages it links
+// to. A Facebook page is replaced by the website it links to, which is returned as the
+// site that was crawled; without one, or when robots.txt disallows the page, the URL is
+// skipped.
+func crawlCompany(ctx context.Context, client HTTPClient, companyURL string) (models.CrawlResult, string, error) {
+	// Validate the URL
+	parsedURL, err := url.ParseRequestURI(companyURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return models.CrawlResult{}, companyURL, fmt.Errorf("%w: %s", models.ErrInvalidCompanyURL, companyURL)
+	}
+
+	crawler := NewCrawler(client, getCrawlOptions())
+
+	if isFacebookURL(companyURL) {
+		website, err := crawler.facebookWebsite(ctx, companyURL)
+		if err != nil {
+			return models.CrawlResult{}, companyURL, fmt.Errorf("%w: %s: %w", models.ErrSkippingFacebookURL, companyURL, err)
+		}
+
+		companyURL = website
+	}
+
+	result, err := crawler.Crawl(ctx, companyURL)
+
+	return result, companyURL, err
+}
+
+// WriteEmailsToFile writes one "company : email" line, followed by " : phone, phone"
+// when phone numbers are given
+func WriteEmailsToFile(file *os.File, companyName, email string, phones ...string) error {
+	line := fmt.Sprintf("%s : %s", companyName, email)
+	if len(phones) > 0 {
+		line += " : " + strings.Join(phones, ", ")
+	}
+
+	_, err := file.WriteString(line + "\n")
 	if err != nil {
 		return fmt.Errorf("%w: %w", models.ErrWriteFileFailed, err)
 	}
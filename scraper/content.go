@@ -0,0 +1,139 @@
+package scraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// documentKind selects how a fetched body is scanned for contact details
+type documentKind int
+
+const (
+	htmlDocument documentKind = iota
+	textDocument
+	pdfDocument
+)
+
+// Media types that are always scanned
+var htmlContentTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// Media types that are only scanned when listed in CrawlOptions.ContentTypes
+var optInContentTypes = map[string]documentKind{
+	"text/plain":      textDocument,
+	"application/pdf": pdfDocument,
+}
+
+// File extensions of the opt-in documents, for following links to them
+var documentExtensions = map[string]string{
+	".txt": "text/plain",
+	".pdf": "application/pdf",
+}
+
+// openBody checks a response against the size limit and accepted content types and
+// returns a reader that fails with models.ErrResponseTooLarge past the limit.
+func (c *Crawler) openBody(resp *http.Response) (*bufio.Reader, documentKind, error) {
+	if c.opts.MaxBodyBytes > 0 && resp.ContentLength > c.opts.MaxBodyBytes {
+		return nil, 0, fmt.Errorf("%w: %d bytes", models.ErrResponseTooLarge, resp.ContentLength)
+	}
+
+	var body io.Reader = resp.Body
+	if c.opts.MaxBodyBytes > 0 {
+		body = &limitedReader{reader: resp.Body, remaining: c.opts.MaxBodyBytes}
+	}
+
+	buffered := bufio.NewReader(body)
+
+	mediaType := responseMediaType(resp.Header, buffered)
+	if htmlContentTypes[mediaType] {
+		return buffered, htmlDocument, nil
+	}
+
+	if kind, ok := optInContentTypes[mediaType]; ok && c.acceptsContentType(mediaType) {
+		return buffered, kind, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w: %s", models.ErrUnsupportedContentType, mediaType)
+}
+
+func (c *Crawler) acceptsContentType(mediaType string) bool {
+	for _, accepted := range c.opts.ContentTypes {
+		if strings.EqualFold(strings.TrimSpace(accepted), mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// documentExtensions returns the extensions of the documents the crawler was opted in to
+// scan, such as ".pdf"
+func (c *Crawler) documentExtensions() map[string]bool {
+	extensions := make(map[string]bool)
+
+	for extension, mediaType := range documentExtensions {
+		if c.acceptsContentType(mediaType) {
+			extensions[extension] = true
+		}
+	}
+
+	return extensions
+}
+
+// responseMediaType returns the media type from the Content-Type header, sniffing
+// the start of the body when the server did not send one
+func responseMediaType(header http.Header, body *bufio.Reader) string {
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			return strings.ToLower(mediaType)
+		}
+	}
+
+	// Peek returns what is available even when the body is shorter than asked
+	start, _ := body.Peek(512)
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(start))
+
+	return mediaType
+}
+
+// limitedReader is io.LimitReader that reports an error instead of a silent EOF
+// when the body is longer than allowed
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// one byte more than allowed means the body is too large
+		var probe [1]byte
+
+		for {
+			n, err := l.reader.Read(probe[:])
+			if n > 0 {
+				return 0, models.ErrResponseTooLarge
+			}
+
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,338 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestCrawlerFetchPageLimits(t *testing.T) {
+	brochure, err := os.ReadFile(filepath.Join("testdata", "pdf", "brochure.pdf"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	kerned, err := os.ReadFile(filepath.Join("testdata", "pdf", "kerned.pdf"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	type dependencies struct {
+		contentType   string
+		contentLength int64
+		body          string
+	}
+
+	type args struct {
+		maxBodyBytes int64
+		contentTypes []string
+		maxPDFPages  int
+	}
+
+	type expected struct {
+		emails []string
+		err    error
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		args         args
+		expected     expected
+	}{
+		{
+			name: "success/HTML within the limit",
+			dependencies: dependencies{
+				contentType: "text/html; charset=utf-8",
+				body:        `<p>info@acme.test</p>`,
+			},
+			args: args{maxBodyBytes: 1024},
+			expected: expected{
+				emails: []string{"info@acme.test"},
+			},
+		},
+		{
+			name: "success/Missing content type is sniffed",
+			dependencies: dependencies{
+				body: `<!DOCTYPE html><html><body>info@acme.test</body></html>`,
+			},
+			args: args{maxBodyBytes: 1024},
+			expected: expected{
+				emails: []string{"info@acme.test"},
+			},
+		},
+		{
+			name: "success/Opted-in plain text",
+			dependencies: dependencies{
+				contentType: "text/plain",
+				body:        "Contact: sales [at] acme [dot] test",
+			},
+			args: args{maxBodyBytes: 1024, contentTypes: []string{"text/plain"}},
+			expected: expected{
+				emails: []string{"sales@acme.test"},
+			},
+		},
+		{
+			name: "success/Opted-in PDF",
+			dependencies: dependencies{
+				contentType: "application/pdf",
+				body:        string(brochure),
+			},
+			args: args{maxBodyBytes: 4096, contentTypes: []string{"application/pdf"}},
+			expected: expected{
+				emails: []string{"sales@acme.test", "press@acme.test"},
+			},
+		},
+		{
+			name: "success/PDF pages past the limit are not read",
+			dependencies: dependencies{
+				contentType: "application/pdf",
+				body:        string(brochure),
+			},
+			args: args{maxBodyBytes: 4096, contentTypes: []string{"application/pdf"}, maxPDFPages: 2},
+			expected: expected{
+				emails: []string{"sales@acme.test"},
+			},
+		},
+		{
+			name: "success/Kerned PDF text",
+			dependencies: dependencies{
+				contentType: "application/pdf",
+				body:        string(kerned),
+			},
+			args: args{maxBodyBytes: 4096, contentTypes: []string{"application/pdf"}},
+			expected: expected{
+				emails: []string{"info@acme.test"},
+			},
+		},
+		{
+			name: "success/No limit",
+			dependencies: dependencies{
+				contentType: "text/html",
+				body:        strings.Repeat(" ", 4096) + `<p>info@acme.test</p>`,
+			},
+			args: args{maxBodyBytes: 0},
+			expected: expected{
+				emails: []string{"info@acme.test"},
+			},
+		},
+		{
+			name: "error/Declared length over the limit",
+			dependencies: dependencies{
+				contentType:   "text/html",
+				contentLength: 4096,
+				body:          `<p>info@acme.test</p>`,
+			},
+			args: args{maxBodyBytes: 1024},
+			expected: expected{
+				err: models.ErrResponseTooLarge,
+			},
+		},
+		{
+			name: "error/Streamed body over the limit",
+			dependencies: dependencies{
+				contentType:   "text/html",
+				contentLength: -1,
+				body:          `<p>info@acme.test</p>` + strings.Repeat("x", 4096),
+			},
+			args: args{maxBodyBytes: 1024},
+			expected: expected{
+				err: models.ErrResponseTooLarge,
+			},
+		},
+		{
+			name: "error/PDF without opt-in",
+			dependencies: dependencies{
+				contentType: "application/pdf",
+				body:        "%PDF-1.7",
+			},
+			args: args{maxBodyBytes: 1024, contentTypes: []string{"text/plain"}},
+			expected: expected{
+				err: models.ErrUnsupportedContentType,
+			},
+		},
+		{
+			name: "error/Malformed PDF",
+			dependencies: dependencies{
+				contentType: "application/pdf",
+				body:        "%PDF-1.7\n1 0 obj\n<< /Type /Catalog",
+			},
+			args: args{maxBodyBytes: 1024, contentTypes: []string{"application/pdf"}},
+			expected: expected{
+				err: models.ErrUnreadablePDF,
+			},
+		},
+		{
+			name: "error/Images are never scanned",
+			dependencies: dependencies{
+				contentType: "image/png",
+				body:        "\x89PNG",
+			},
+			args: args{maxBodyBytes: 1024, contentTypes: []string{"image/png"}},
+			expected: expected{
+				err: models.ErrUnsupportedContentType,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/robots.txt" {
+						return mockHTTPResponse(http.StatusNotFound, ""), nil
+					}
+
+					resp := mockHTTPResponse(http.StatusOK, tc.dependencies.body)
+					resp.ContentLength = tc.dependencies.contentLength
+
+					if tc.dependencies.contentType != "" {
+						resp.Header.Set("Content-Type", tc.dependencies.contentType)
+					}
+
+					return resp, nil
+				},
+			}
+
+			opts := DefaultCrawlOptions()
+			opts.MaxBodyBytes = tc.args.maxBodyBytes
+			opts.ContentTypes = tc.args.contentTypes
+			opts.MaxPDFPages = tc.args.maxPDFPages
+
+			scanner, _, err := NewCrawler(client, opts).fetchPage(context.Background(), "https://acme.test/")
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var emails []string
+			for _, candidate := range scanner.candidates {
+				emails = append(emails, candidate.Address)
+			}
+
+			assert.Equal(t, tc.expected.emails, emails)
+		})
+	}
+}
+
+func TestCrawlerFetchPageCharsets(t *testing.T) {
+	type dependencies struct {
+		fixture     string
+		contentType string
+	}
+
+	type expected struct {
+		linkText string
+		emails   []string
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		expected     expected
+	}{
+		{
+			name: "success/Shift_JIS from a meta charset",
+			dependencies: dependencies{
+				fixture:     "shift_jis.html",
+				contentType: "text/html",
+			},
+			expected: expected{linkText: "お問い合わせ", emails: []string{"info@acme.test"}},
+		},
+		{
+			name: "success/windows-1251 from the Content-Type header",
+			dependencies: dependencies{
+				fixture:     "windows-1251.html",
+				contentType: "text/html; charset=windows-1251",
+			},
+			expected: expected{linkText: "Контакты", emails: []string{"info@acme.test"}},
+		},
+		{
+			name: "success/ISO-8859-1 from an http-equiv meta",
+			dependencies: dependencies{
+				fixture:     "iso-8859-1.html",
+				contentType: "text/html",
+			},
+			expected: expected{linkText: "Über uns - Café", emails: []string{"info@acme.test"}},
+		},
+		{
+			name: "success/UTF-16 byte order mark overrides the header",
+			dependencies: dependencies{
+				fixture:     "utf-16le-bom.html",
+				contentType: "text/html; charset=iso-8859-1",
+			},
+			expected: expected{linkText: "Contáctenos", emails: []string{"info@acme.test"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "charset", tc.dependencies.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			client := &MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/robots.txt" {
+						return mockHTTPResponse(http.StatusNotFound, ""), nil
+					}
+
+					resp := mockHTTPResponse(http.StatusOK, string(body))
+					resp.Header.Set("Content-Type", tc.dependencies.contentType)
+
+					return resp, nil
+				},
+			}
+
+			scanner, _, err := NewCrawler(client, DefaultCrawlOptions()).fetchPage(context.Background(), "https://acme.test/")
+			assert.NoError(t, err)
+
+			var emails []string
+			for _, candidate := range scanner.candidates {
+				emails = append(emails, candidate.Address)
+			}
+
+			assert.Equal(t, tc.expected.emails, emails)
+
+			if assert.Len(t, scanner.links, 1) {
+				assert.Equal(t, tc.expected.linkText, scanner.links[0].text)
+			}
+		})
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		expected    string
+	}{
+		{name: "Undeclared UTF-8 is kept", body: "<p>Straße</p>", contentType: "text/html", expected: "<p>Straße</p>"},
+		{name: "Undeclared legacy bytes fall back to windows-1252", body: "<p>Stra\xdfe</p>", contentType: "text/html", expected: "<p>Straße</p>"},
+		{name: "Declared charset wins", body: "<p>Stra\xdfe</p>", contentType: "text/html; charset=iso-8859-1", expected: "<p>Straße</p>"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := decodeToUTF8(strings.NewReader(tc.body), tc.contentType)
+			assert.NoError(t, err)
+
+			text, err := io.ReadAll(decoded)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(text))
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// CrawlOptions bounds how much of a company site is fetched.
+type CrawlOptions struct {
+	MaxDepth int  // link hops followed from the start page
+	MaxPages int  // pages fetched in total, including the start page
+	Sitemaps bool // also queue contact-like pages listed in robots.txt and /sitemap.xml sitemaps
+
+	// UserAgent identifies the crawler and how to reach its operator. Its product token
+	// (the part before the first "/") is matched against robots.txt user-agent groups.
+	UserAgent string
+	// UserAgents is an optional pool; each site gets one of them instead of UserAgent.
+	UserAgents []string
+	// Headers are sent with every request, overriding the default Accept headers.
+	Headers map[string]string
+
+	// MaxBodyBytes caps how much of a response is read; 0 means no limit.
+	MaxBodyBytes int64
+	// ContentTypes opts in to scanning non-HTML responses: text/plain and application/pdf.
+	// Links to .txt and .pdf documents are then followed like contact pages.
+	ContentTypes []string
+	// MaxPDFPages caps how many pages of a PDF document are read; 0 means no limit.
+	MaxPDFPages int
+}
+
+// DefaultCrawlOptions visits the start page and up to four contact-like pages it or
+// the site's sitemaps link to.
+func DefaultCrawlOptions() CrawlOptions {
+	return CrawlOptions{
+		MaxDepth: 1,
+		MaxPages: 5,
+		Sitemaps: true,
+
+		UserAgent: defaultUserAgent,
+		Headers: map[string]string{
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			"Accept-Language": "en-US,en;q=0.9",
+		},
+
+		MaxBodyBytes: 5 << 20,
+		MaxPDFPages:  20,
+	}
+}
+
+func getCrawlOptions() CrawlOptions {
+	opts := DefaultCrawlOptions()
+
+	if viper.IsSet("crawler.max_depth") {
+		opts.MaxDepth = viper.GetInt("crawler.max_depth")
+	}
+
+	if viper.IsSet("crawler.max_pages") {
+		opts.MaxPages = viper.GetInt("crawler.max_pages")
+	}
+
+	if viper.IsSet("crawler.sitemaps") {
+		opts.Sitemaps = viper.GetBool("crawler.sitemaps")
+	}
+
+	if userAgent := viper.GetString("crawler.user_agent"); userAgent != "" {
+		opts.UserAgent = userAgent
+	}
+
+	opts.UserAgents = viper.GetStringSlice("crawler.user_agents")
+
+	// viper lower-cases keys read from the config file, so they are canonicalized to
+	// replace the defaults
+	for name, value := range viper.GetStringMapString("crawler.headers") {
+		opts.Headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	if viper.IsSet("crawler.max_body_bytes") {
+		opts.MaxBodyBytes = viper.GetInt64("crawler.max_body_bytes")
+	}
+
+	opts.ContentTypes = viper.GetStringSlice("crawler.content_types")
+
+	if viper.IsSet("crawler.max_pdf_pages") {
+		opts.MaxPDFPages = viper.GetInt("crawler.max_pdf_pages")
+	}
+
+	return opts
+}
+
+// Keywords in a link's path or text that suggest a page carrying contact details,
+// weighted by how likely the page is to list an email
+var contactPageKeywords = map[string]int{
+	"contact":   3,
+	"kontakt":   3,
+	"contacto":  3,
+	"contatti":  3,
+	"impressum": 3,
+	"imprint":   3,
+	"touch":     2, // "get in touch"
+	"about":     2,
+	"legal":     2,
+	"team":      2,
+	"company":   1,
+	"support":   1,
+	"privacy":   1,
+	// non-Latin scripts, matched after the page is decoded to UTF-8
+	"контакт":    3,
+	"о компании": 2,
+	"お問い合わせ":     3,
+	"会社概要":       2,
+	"联系":         3,
+}
+
+// Extensions of links that never lead to an HTML page
+var nonPageExtensions = map[string]bool{
+	".pdf": true, ".zip": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+	".mp4": true, ".mp3": true, ".css": true, ".js": true, ".xml": true,
+}
+
+// Crawler fetches a company's start page and the contact, about and imprint pages
+// it links to on the same site, honoring each origin's robots.txt.
+type Crawler struct {
+	client HTTPClient
+	opts   CrawlOptions
+	sites  *siteState
+}
+
+// NewCrawler returns a crawler sending its requests through client. With a FetchClient,
+// robots.txt policies and Crawl-delay are shared with the other crawlers using it;
+// otherwise they last for this crawler only.
+func NewCrawler(client HTTPClient, opts CrawlOptions) *Crawler {
+	sites := newSiteState()
+	if fetchClient, ok := client.(*FetchClient); ok {
+		sites = fetchClient.sites
+	}
+
+	return &Crawler{client: client, opts: opts, sites: sites}
+}
+
+type queuedPage struct {
+	url   string
+	depth int
+}
+
+// Crawl visits startURL and then likely contact pages breadth first, until the depth
+// or page budget is spent or ctx is done. It fails only when the start page itself cannot
+// be read; failures on other pages, and pages robots.txt disallows, are recorded in the
+// result.
+func (c *Crawler) Crawl(ctx context.Context, startURL string) (models.CrawlResult, error) {
+	var result crawlResult
+
+	startURL = asciiURL(startURL)
+
+	queue := []queuedPage{{url: startURL}}
+	seen := map[string]bool{normalizePageURL(startURL): true}
+	siteHost := hostOf(startURL)
+
+	enqueue := func(links []string, depth int) {
+		for _, link := range links {
+			link = asciiURL(link)
+			if key := normalizePageURL(link); !seen[key] {
+				seen[key] = true
+				queue = append(queue, queuedPage{url: link, depth: depth})
+			}
+		}
+	}
+
+	for len(queue) > 0 && len(result.Pages) < max(c.opts.MaxPages, 1) {
+		page := queue[0]
+		queue = queue[1:]
+
+		scanner, finalURL, err := c.fetchPage(ctx, page.url)
+		if err != nil {
+			result.Pages = append(result.Pages, models.PageResult{URL: page.url, Depth: page.depth, Status: pageStatus(err), Err: err})
+
+			if page.depth == 0 {
+				return models.CrawlResult(result), err
+			}
+
+			continue
+		}
+
+		result.Pages = append(result.Pages, models.PageResult{URL: page.url, Depth: page.depth, Status: models.PageFetched})
+
+		if page.depth == 0 {
+			siteHost = hostOf(finalURL)
+
+			// sitemap fetches count against the page budget
+			if c.opts.Sitemaps && c.opts.MaxDepth > 0 {
+				pages, sitemaps := c.sitemapPages(ctx, finalURL, siteHost, max(c.opts.MaxPages, 1)-len(result.Pages))
+				result.Pages = append(result.Pages, sitemaps...)
+				enqueue(pages, 1)
+			}
+		}
+
+		result.add(scanner, finalURL)
+
+		if page.depth >= c.opts.MaxDepth {
+			continue
+		}
+
+		enqueue(discoverContactPages(scanner.links, finalURL, siteHost, c.documentExtensions()), page.depth+1)
+	}
+
+	return models.CrawlResult(result), nil
+}
+
+// crawlResult is the result of a crawl while its pages are being scanned
+type crawlResult models.CrawlResult
+
+// add merges what was found on a page into the result, recording the page it came from
+func (r *crawlResult) add(scanner *pageScanner, pageURL string) {
+	for _, candidate := range scanner.candidates {
+		candidate.PageURL = pageURL
+		r.Emails = append(r.Emails, candidate)
+	}
+
+	for _, phone := range scanner.phones {
+		phone.PageURL = pageURL
+		r.Phones = append(r.Phones, phone)
+	}
+
+	for _, profile := range scanner.profiles {
+		profile.PageURL = pageURL
+		r.Profiles = append(r.Profiles, profile)
+	}
+
+	for _, address := range scanner.addresses {
+		address.PageURL = pageURL
+		r.Addresses = append(r.Addresses, address)
+	}
+
+	r.Names = append(r.Names, scanner.names...)
+	r.Profiles = append(r.Profiles, socialProfilesFromLinks(scanner.links, pageURL)...)
+}
+
+// pageStatus classifies the outcome of fetching a page
+func pageStatus(err error) models.PageStatus {
+	switch {
+	case err == nil:
+		return models.PageFetched
+	case errors.Is(err, models.ErrDisallowedByRobots):
+		return models.PageDisallowed
+	default:
+		return models.PageFailed
+	}
+}
+
+// fetchPage downloads and scans a single page, returning the URL it was served
+// from after redirects so relative links resolve correctly.
+func (c *Crawler) fetchPage(ctx context.Context, pageURL string) (*pageScanner, string, error) {
+	scanner := &pageScanner{}
+	finalURL := pageURL
+
+	err := c.get(ctx, pageURL, func(resp *http.Response) error {
+		if resp.Request != nil && resp.Request.URL != nil {
+			finalURL = resp.Request.URL.String()
+		}
+
+		body, kind, err := c.openBody(resp)
+		if err != nil {
+			return err
+		}
+
+		var text io.Reader
+		if kind == pdfDocument {
+			text, err = pdfText(body, c.opts.MaxPDFPages)
+		} else {
+			text, err = decodeToUTF8(body, resp.Header.Get("Content-Type"))
+		}
+
+		switch {
+		case err != nil:
+		case kind == htmlDocument:
+			err = scanner.scan(text)
+		default:
+			err = scanner.scanText(text)
+		}
+
+		if errors.Is(err, models.ErrResponseTooLarge) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("%w: %w", models.ErrReadFailed, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return scanner, finalURL, nil
+}
+
+// get requests rawURL, if robots.txt allows it, and hands a 200 OK response to read
+// before the body is closed
+func (c *Crawler) get(ctx context.Context, rawURL string, read func(resp *http.Response) error) error {
+	if err := c.checkRobots(ctx, rawURL); err != nil {
+		return err
+	}
+
+	return c.do(ctx, rawURL, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%w: %s", models.ErrNonOKStatus, resp.Status)
+		}
+
+		return read(resp)
+	})
+}
+
+// do sends a GET request for rawURL and hands the response to read before the body is closed
+func (c *Crawler) do(ctx context.Context, rawURL string, read func(resp *http.Response) error) error {
+	// Create a context with a timeout
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", models.ErrFetchFailed, err)
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", models.ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	return read(resp)
+}
+
+// discoverContactPages resolves the links of a page and returns the same-site ones that
+// look like contact pages, most promising first. Links to files are skipped unless their
+// extension is one of the documents scanned.
+func discoverContactPages(links []pageLink, pageURL, siteHost string, documents map[string]bool) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var found []scoredLink
+
+	for _, link := range links {
+		target, err := base.Parse(link.href)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			continue
+		}
+
+		extension := strings.ToLower(path.Ext(target.Path))
+		if hostOf(target.String()) != siteHost || (nonPageExtensions[extension] && !documents[extension]) {
+			continue
+		}
+
+		score := contactPageScore(target.Path, link.text)
+		if score == 0 {
+			continue
+		}
+
+		if link.inFooter {
+			score++
+		}
+
+		target.Fragment = ""
+		found = append(found, scoredLink{url: asciiURL(target.String()), score: score})
+	}
+
+	return byScore(found)
+}
+
+type scoredLink struct {
+	url   string
+	score int
+}
+
+// byScore returns the URLs of the links, highest score first
+func byScore(links []scoredLink) []string {
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].score > links[j].score
+	})
+
+	urls := make([]string, 0, len(links))
+	for _, link := range links {
+		urls = append(urls, link.url)
+	}
+
+	return urls
+}
+
+func contactPageScore(linkPath, linkText string) int {
+	haystack := strings.ToLower(linkPath + " " + linkText)
+
+	var score int
+
+	for keyword, weight := range contactPageKeywords {
+		if strings.Contains(haystack, keyword) {
+			score = max(score, weight)
+		}
+	}
+
+	return score
+}
+
+// normalizePageURL gives the key used to avoid fetching the same page twice
+func normalizePageURL(pageURL string) string {
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+
+	parsedURL.Fragment = ""
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+
+	return parsedURL.String()
+}
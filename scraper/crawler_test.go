@@ -0,0 +1,226 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// newTestSite serves the given pages by path; every other path returns 404
+func newTestSite(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		_, err := w.Write([]byte(page))
+		if err != nil {
+			t.Error("failed to write mock response")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCrawlerCrawl(t *testing.T) {
+	site := map[string]string{
+		"/": `<html><body><nav><a href="/products">Products</a><a href="/about-us">About us</a></nav>` +
+			`<a href="https://other.example/contact">Partner</a>` +
+			`<footer><a href="/impressum">Impressum</a><a href="/kontakt#form">Get in touch</a></footer></body></html>`,
+		"/kontakt":   `<p>Write to info@acme.test</p><a href="/team">Team</a>`,
+		"/impressum": `<p>Responsible: legal@acme.test</p>`,
+		"/about-us":  `<p>Founded in 1999</p>`,
+		"/team":      `<a href="mailto:jane@acme.test">Jane</a>`,
+		"/products":  `<p>sales@acme.test</p>`,
+	}
+
+	type args struct {
+		opts  CrawlOptions
+		start string
+	}
+
+	type expected struct {
+		pages  []string
+		emails []string
+		err    error
+	}
+
+	tests := []struct {
+		name     string
+		pages    map[string]string
+		args     args
+		expected expected
+	}{
+		{
+			name:  "success/Follows contact-like links by priority",
+			pages: site,
+			args: args{
+				opts:  CrawlOptions{MaxDepth: 1, MaxPages: 10},
+				start: "/",
+			},
+			expected: expected{
+				pages:  []string{"/", "/impressum", "/kontakt", "/about-us"},
+				emails: []string{"legal@acme.test", "info@acme.test"},
+			},
+		},
+		{
+			name:  "success/Depth allows following links from contact pages",
+			pages: site,
+			args: args{
+				opts:  CrawlOptions{MaxDepth: 2, MaxPages: 10},
+				start: "/",
+			},
+			expected: expected{
+				pages:  []string{"/", "/impressum", "/kontakt", "/about-us", "/team"},
+				emails: []string{"legal@acme.test", "info@acme.test", "jane@acme.test"},
+			},
+		},
+		{
+			name:  "success/Page budget stops the crawl",
+			pages: site,
+			args: args{
+				opts:  CrawlOptions{MaxDepth: 2, MaxPages: 2},
+				start: "/",
+			},
+			expected: expected{
+				pages:  []string{"/", "/impressum"},
+				emails: []string{"legal@acme.test"},
+			},
+		},
+		{
+			name:  "success/Zero depth only visits the start page",
+			pages: site,
+			args: args{
+				opts:  CrawlOptions{MaxDepth: 0, MaxPages: 10},
+				start: "/",
+			},
+			expected: expected{
+				pages:  []string{"/"},
+				emails: nil,
+			},
+		},
+		{
+			name: "success/Broken contact page is recorded and skipped",
+			pages: map[string]string{
+				"/":      `<a href="/contact">Contact</a><a href="/about">About</a>`,
+				"/about": `<p>hello@acme.test</p>`,
+			},
+			args: args{
+				opts:  DefaultCrawlOptions(),
+				start: "/",
+			},
+			expected: expected{
+				pages:  []string{"/", "/sitemap.xml", "/contact", "/about"},
+				emails: []string{"hello@acme.test"},
+			},
+		},
+		{
+			name:  "error/Start page not found",
+			pages: site,
+			args: args{
+				opts:  DefaultCrawlOptions(),
+				start: "/missing",
+			},
+			expected: expected{
+				err: models.ErrNonOKStatus,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestSite(t, tc.pages)
+
+			result, err := NewCrawler(server.Client(), tc.args.opts).Crawl(context.Background(), server.URL+tc.args.start)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var pages []string
+			for _, page := range result.Pages {
+				pages = append(pages, page.URL[len(server.URL):])
+			}
+
+			var emails []string
+			for _, email := range result.Emails {
+				emails = append(emails, email.Address)
+				assert.NotEmpty(t, email.PageURL)
+			}
+
+			assert.Equal(t, tc.expected.pages, pages)
+			assert.Equal(t, tc.expected.emails, emails)
+		})
+	}
+}
+
+func TestDiscoverContactPages(t *testing.T) {
+	links := []pageLink{
+		{href: "/", text: "Home"},
+		{href: "about.html", text: "Who we are"},
+		{href: "/brochure-contact.pdf", text: "Contact sheet"},
+		{href: "mailto:info@acme.test", text: "Contact"},
+		{href: "https://shop.acme.test/contact", text: "Shop"},
+		{href: "https://www.acme.test/en/legal", text: "Legal notice", inFooter: true},
+		{href: "javascript:void(0)", text: "Contact"},
+		{href: "/company/contact-us#map", text: "Reach us"},
+	}
+
+	urls := discoverContactPages(links, "https://acme.test/en/index.html", "acme.test", nil)
+
+	assert.Equal(t, []string{
+		"https://www.acme.test/en/legal",
+		"https://acme.test/company/contact-us",
+		"https://acme.test/en/about.html",
+	}, urls)
+
+	urls = discoverContactPages(links, "https://acme.test/en/index.html", "acme.test", map[string]bool{".pdf": true})
+
+	assert.Equal(t, []string{
+		"https://acme.test/brochure-contact.pdf",
+		"https://www.acme.test/en/legal",
+		"https://acme.test/company/contact-us",
+		"https://acme.test/en/about.html",
+	}, urls)
+}
+
+func TestContactPageScore(t *testing.T) {
+	type args struct {
+		linkPath string
+		linkText string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected int
+	}{
+		{name: "Contact path", args: args{linkPath: "/contact-us", linkText: "Write to us"}, expected: 3},
+		{name: "Russian link text", args: args{linkPath: "/info", linkText: "Контакты"}, expected: 3},
+		{name: "Japanese link text", args: args{linkPath: "/inquiry", linkText: "お問い合わせ"}, expected: 3},
+		{name: "Russian about page", args: args{linkPath: "/o-nas", linkText: "О компании"}, expected: 2},
+		{name: "Unrelated link", args: args{linkPath: "/blog", linkText: "News"}, expected: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, contactPageScore(tc.args.linkPath, tc.args.linkText))
+		})
+	}
+}
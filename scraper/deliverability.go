@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Resolver looks up the DNS records that decide whether a domain can receive mail.
+// *net.Resolver implements it.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DeliverabilityOptions configures the MX check of the selected emails.
+type DeliverabilityOptions struct {
+	Enabled   bool
+	Timeout   time.Duration // per DNS lookup
+	DNSServer string        // "host:port" of the DNS server to query; empty uses the system resolver
+}
+
+// DefaultDeliverabilityOptions leaves the check off, as it sends DNS queries for every
+// domain found.
+func DefaultDeliverabilityOptions() DeliverabilityOptions {
+	return DeliverabilityOptions{
+		Timeout: 5 * time.Second,
+	}
+}
+
+func getDeliverabilityOptions() DeliverabilityOptions {
+	opts := DefaultDeliverabilityOptions()
+
+	if viper.IsSet("deliverability.enabled") {
+		opts.Enabled = viper.GetBool("deliverability.enabled")
+	}
+
+	if viper.IsSet("deliverability.timeout") {
+		opts.Timeout = viper.GetDuration("deliverability.timeout")
+	}
+
+	if viper.IsSet("deliverability.dns_server") {
+		opts.DNSServer = viper.GetString("deliverability.dns_server")
+	}
+
+	return opts
+}
+
+// NewResolver returns the system resolver, or one that sends every query to dnsServer
+func NewResolver(dnsServer string) Resolver {
+	if dnsServer == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+
+			return dialer.DialContext(ctx, network, dnsServer)
+		},
+	}
+}
+
+// DeliverabilityChecker finds out from DNS whether email domains can receive mail,
+// caching the answer per domain for the lifetime of the checker.
+type DeliverabilityChecker struct {
+	resolver Resolver
+	opts     DeliverabilityOptions
+
+	mu    sync.Mutex
+	cache map[string]models.Deliverability
+}
+
+func NewDeliverabilityChecker(resolver Resolver, opts DeliverabilityOptions) *DeliverabilityChecker {
+	return &DeliverabilityChecker{
+		resolver: resolver,
+		opts:     opts,
+		cache:    make(map[string]models.Deliverability),
+	}
+}
+
+// NewDeliverabilityCheckerFromConfig returns a checker built from the "deliverability"
+// settings, or nil when the check is disabled
+func NewDeliverabilityCheckerFromConfig() *DeliverabilityChecker {
+	opts := getDeliverabilityOptions()
+	if !opts.Enabled {
+		return nil
+	}
+
+	return NewDeliverabilityChecker(NewResolver(opts.DNSServer), opts)
+}
+
+// CheckEmails sets the Deliverability of every ranked email from its domain
+func (c *DeliverabilityChecker) CheckEmails(ctx context.Context, ranked []models.RankedEmail) {
+	for i := range ranked {
+		address := ranked[i].ASCII
+		if address == "" {
+			address = ranked[i].Address
+		}
+
+		ranked[i].Deliverability = c.Check(ctx, address[strings.LastIndex(address, "@")+1:])
+	}
+}
+
+// Check looks up the MX records of an ASCII domain, falling back to its address records
+// when there are none. Lookup failures are reported as unknown and are not cached, so a
+// later email on the same domain tries again.
+func (c *DeliverabilityChecker) Check(ctx context.Context, domain string) models.Deliverability {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	c.mu.Lock()
+	deliverability, cached := c.cache[domain]
+	c.mu.Unlock()
+
+	if cached {
+		return deliverability
+	}
+
+	deliverability = c.lookup(ctx, domain)
+	if deliverability != models.DeliverabilityUnknown {
+		c.mu.Lock()
+		c.cache[domain] = deliverability
+		c.mu.Unlock()
+	}
+
+	return deliverability
+}
+
+func (c *DeliverabilityChecker) lookup(ctx context.Context, domain string) models.Deliverability {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	records, err := c.resolver.LookupMX(ctx, domain)
+
+	switch {
+	case err != nil && !isNotFound(err):
+		return models.DeliverabilityUnknown
+	case len(records) == 1 && records[0].Host == ".":
+		// null MX: the domain explicitly accepts no mail
+		return models.Undeliverable
+	case len(records) > 0:
+		return models.DeliverableMX
+	}
+
+	addresses, err := c.resolver.LookupHost(ctx, domain)
+
+	switch {
+	case err != nil && !isNotFound(err):
+		return models.DeliverabilityUnknown
+	case len(addresses) > 0:
+		return models.DeliverableA
+	default:
+		return models.Undeliverable
+	}
+}
+
+// isNotFound reports whether a lookup error means the name or record type does not exist
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,207 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// fakeRecords are the records a fake DNS server holds for one name
+type fakeRecords struct {
+	mx       []string // exchange host names, "." for a null MX
+	a        []string
+	servfail bool
+}
+
+// fakeDNSServer answers MX, A and AAAA queries over UDP from a fixed zone and
+// counts the queries it receives per name
+type fakeDNSServer struct {
+	conn net.PacketConn
+	zone map[string]fakeRecords // by lower-case name without the trailing dot
+
+	mu      sync.Mutex
+	queries map[string]int
+}
+
+func newFakeDNSServer(t *testing.T, zone map[string]fakeRecords) *fakeDNSServer {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake DNS server: %v", err)
+	}
+
+	server := &fakeDNSServer{conn: conn, zone: zone, queries: make(map[string]int)}
+
+	go server.serve()
+
+	t.Cleanup(func() { conn.Close() })
+
+	return server
+}
+
+func (s *fakeDNSServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *fakeDNSServer) count(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queries[name]
+}
+
+func (s *fakeDNSServer) serve() {
+	buf := make([]byte, 512)
+
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if reply, err := s.answer(buf[:n]); err == nil {
+			_, _ = s.conn.WriteTo(reply, addr)
+		}
+	}
+}
+
+func (s *fakeDNSServer) answer(query []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+
+	s.mu.Lock()
+	s.queries[name]++
+	s.mu.Unlock()
+
+	records, found := s.zone[name]
+
+	reply := dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true, RecursionAvailable: true}
+
+	switch {
+	case !found:
+		reply.RCode = dnsmessage.RCodeNameError
+	case records.servfail:
+		reply.RCode = dnsmessage.RCodeServerFailure
+	}
+
+	builder := dnsmessage.NewBuilder(nil, reply)
+	builder.EnableCompression()
+
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	resource := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}
+
+	switch question.Type {
+	case dnsmessage.TypeMX:
+		for i, host := range records.mx {
+			mx := dnsmessage.MXResource{Pref: uint16(10 * (i + 1)), MX: dnsmessage.MustNewName(host)}
+			if err := builder.MXResource(resource, mx); err != nil {
+				return nil, err
+			}
+		}
+	case dnsmessage.TypeA:
+		for _, ip := range records.a {
+			var a dnsmessage.AResource
+
+			copy(a.A[:], net.ParseIP(ip).To4())
+
+			if err := builder.AResource(resource, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return builder.Finish()
+}
+
+func TestDeliverabilityCheckerCheck(t *testing.T) {
+	zone := map[string]fakeRecords{
+		"acme.test":       {mx: []string{"mx1.acme.test.", "mx2.acme.test."}},
+		"a-only.test":     {a: []string{"192.0.2.10"}},
+		"null-mx.test":    {mx: []string{"."}, a: []string{"192.0.2.20"}},
+		"no-records.test": {},
+		"broken.test":     {servfail: true},
+	}
+
+	tests := []struct {
+		name     string
+		domain   string
+		expected models.Deliverability
+	}{
+		{name: "MX records", domain: "acme.test", expected: models.DeliverableMX},
+		{name: "Domain is matched case-insensitively", domain: "ACME.test.", expected: models.DeliverableMX},
+		{name: "A record fallback", domain: "a-only.test", expected: models.DeliverableA},
+		{name: "Null MX", domain: "null-mx.test", expected: models.Undeliverable},
+		{name: "No MX or address records", domain: "no-records.test", expected: models.Undeliverable},
+		{name: "Domain does not exist", domain: "missing.test", expected: models.Undeliverable},
+		{name: "Server failure", domain: "broken.test", expected: models.DeliverabilityUnknown},
+	}
+
+	server := newFakeDNSServer(t, zone)
+	checker := NewDeliverabilityChecker(NewResolver(server.addr()), DeliverabilityOptions{Timeout: 2 * time.Second})
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, checker.Check(context.Background(), tc.domain))
+		})
+	}
+}
+
+func TestDeliverabilityCheckerCache(t *testing.T) {
+	server := newFakeDNSServer(t, map[string]fakeRecords{
+		"acme.test":   {mx: []string{"mx.acme.test."}},
+		"broken.test": {servfail: true},
+	})
+
+	checker := NewDeliverabilityChecker(NewResolver(server.addr()), DeliverabilityOptions{Timeout: 2 * time.Second})
+
+	ranked := []models.RankedEmail{
+		{Address: "info@acme.test", ASCII: "info@acme.test"},
+		{Address: "sales@acme.test", ASCII: "sales@acme.test"},
+		{Address: "info@broken.test"},
+	}
+
+	checker.CheckEmails(context.Background(), ranked)
+
+	assert.Equal(t, models.DeliverableMX, ranked[0].Deliverability)
+	assert.Equal(t, models.DeliverableMX, ranked[1].Deliverability)
+	assert.Equal(t, models.DeliverabilityUnknown, ranked[2].Deliverability)
+
+	answered, failed := server.count("acme.test"), server.count("broken.test")
+
+	assert.Equal(t, models.DeliverableMX, checker.Check(context.Background(), "acme.test"))
+	assert.Equal(t, answered, server.count("acme.test"), "answers should be cached per domain")
+
+	assert.Equal(t, models.DeliverabilityUnknown, checker.Check(context.Background(), "broken.test"))
+	assert.Greater(t, server.count("broken.test"), failed, "failed lookups should be retried")
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Path Cloudflare rewrites mailto: links to when email obfuscation is enabled
+const cloudflareProtectionPath = "/cdn-cgi/l/email-protection#"
+
+var (
+	// "info [at] acme [dot] com", "info(at)acme(dot)com", "info{@}acme{.}com"
+	bracketedAtRegex  = regexp.MustCompile(`(?i)\s*[\[({<]\s*(?:at|@)\s*[\])}>]\s*`)
+	bracketedDotRegex = regexp.MustCompile(`(?i)\s*[\[({<]\s*(?:dot|\.)\s*[\])}>]\s*`)
+
+	// "info AT acme DOT com": only upper case, and only when both words appear,
+	// so ordinary sentences like "find us at acme dot com" are left alone
+	spelledEmailRegex = regexp.MustCompile(`[\p{L}\p{M}\p{N}._%+-]+\s+AT\s+[\p{L}\p{M}\p{N}-]+(?:\s+DOT\s+[\p{L}\p{M}\p{N}-]+)+`)
+	spelledAtRegex    = regexp.MustCompile(`\s+AT\s+`)
+	spelledDotRegex   = regexp.MustCompile(`\s+DOT\s+`)
+)
+
+// deobfuscateText rewrites the common human-readable obfuscations of "@" and "."
+// into their literal characters. Text without obfuscation is returned unchanged.
+func deobfuscateText(text string) string {
+	text = bracketedAtRegex.ReplaceAllString(text, "@")
+	text = bracketedDotRegex.ReplaceAllString(text, ".")
+
+	return spelledEmailRegex.ReplaceAllStringFunc(text, func(match string) string {
+		match = spelledAtRegex.ReplaceAllString(match, "@")
+
+		return spelledDotRegex.ReplaceAllString(match, ".")
+	})
+}
+
+// newCandidates returns the deobfuscated candidates that were not already found
+// verbatim in the same text, so an address is not counted twice for one occurrence.
+func newCandidates(found, deobfuscated []models.EmailCandidate) []models.EmailCandidate {
+	seen := make(map[string]int, len(found))
+	for _, candidate := range found {
+		seen[candidate.Address]++
+	}
+
+	var candidates []models.EmailCandidate
+
+	for _, candidate := range deobfuscated {
+		if seen[candidate.Address] > 0 {
+			seen[candidate.Address]--
+
+			continue
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// isReversedText reports whether an element renders its text right-to-left with
+// bidi-override, the CSS trick used to display "moc.emca@ofni" as "info@acme.com".
+func isReversedText(token html.Token) bool {
+	for _, attr := range token.Attr {
+		if !strings.EqualFold(attr.Key, "style") {
+			continue
+		}
+
+		style := strings.ToLower(strings.ReplaceAll(attr.Val, " ", ""))
+
+		return strings.Contains(style, "direction:rtl") && strings.Contains(style, "unicode-bidi:bidi-override")
+	}
+
+	return false
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
+// emailsFromCloudflareLink decodes links of the form /cdn-cgi/l/email-protection#<hex>
+func emailsFromCloudflareLink(href string) []models.EmailCandidate {
+	_, encoded, found := strings.Cut(href, cloudflareProtectionPath)
+	if !found {
+		return nil
+	}
+
+	decoded := decodeCloudflareEmail(encoded)
+	if candidates := emailsFromMailto(decoded, models.SourceCloudflare); candidates != nil {
+		return candidates
+	}
+
+	return findEmails(decoded, models.SourceCloudflare)
+}
+
+// decodeCloudflareEmail reverses Cloudflare's email protection: the first byte of
+// the hex string is an XOR key applied to every following byte.
+func decodeCloudflareEmail(encoded string) string {
+	data, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(data) < 2 {
+		return ""
+	}
+
+	key := data[0]
+	decoded := make([]byte, 0, len(data)-1)
+
+	for _, b := range data[1:] {
+		decoded = append(decoded, b^key)
+	}
+
+	return string(decoded)
+}
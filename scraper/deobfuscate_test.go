@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Patterns collected from real company pages that hide their addresses
+func TestExtractEmailCandidatesObfuscated(t *testing.T) {
+	type args struct {
+		document string
+	}
+
+	type expected struct {
+		candidates []models.EmailCandidate
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Square brackets with spaces",
+			args: args{
+				document: `<p>Email: info [at] acme [dot] com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Parentheses without spaces and multi-part domain",
+			args: args{
+				document: `<p>sales(at)acme(dot)co(dot)uk</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "sales@acme.co.uk", ASCII: "sales@acme.co.uk", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Curly braces around symbols",
+			args: args{
+				document: `<p>hello{@}acme{.}io</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "hello@acme.io", ASCII: "hello@acme.io", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Mixed case bracketed words with literal dot",
+			args: args{
+				document: `<p>support [AT] acme.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "support@acme.com", ASCII: "support@acme.com", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Spelled out upper case words",
+			args: args{
+				document: `<p>Write to jobs AT acme DOT com today</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "jobs@acme.com", ASCII: "jobs@acme.com", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Plain sentence is not rewritten",
+			args: args{
+				document: `<p>Find us at acme dot com or visit us at the office</p>`,
+			},
+			expected: expected{
+				candidates: nil,
+			},
+		},
+		{
+			name: "success/Literal and obfuscated addresses in one text are both kept once",
+			args: args{
+				document: `<p>info@acme.com or sales [at] acme [dot] com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+					{Address: "sales@acme.com", ASCII: "sales@acme.com", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Decimal and hex HTML entities",
+			args: args{
+				document: `<p>&#105;&#110;&#102;&#111;&#64;acme&#x2e;com</p><p>press&commat;acme&period;com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+					{Address: "press@acme.com", ASCII: "press@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Reversed text shown with bidi override",
+			args: args{
+				document: `<span style="unicode-bidi: bidi-override; direction: rtl;">moc.emca@ofni</span>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceObfuscated},
+				},
+			},
+		},
+		{
+			name: "success/Reversed text ends with its element",
+			args: args{
+				document: `<span style="direction:rtl;unicode-bidi:bidi-override"><span>moc.emca</span>@ofni</span>` +
+					`<p>team@acme.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "team@acme.com", ASCII: "team@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Cloudflare data-cfemail span",
+			args: args{
+				document: `<span class="__cf_email__" data-cfemail="422b2c242d0223212f276c212d2f">[email&#160;protected]</span>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceCloudflare},
+				},
+			},
+		},
+		{
+			name: "success/Cloudflare email protection links",
+			args: args{
+				document: `<a href="/cdn-cgi/l/email-protection#7a0a081f09093a1b19171f54191517">Press</a>` +
+					`<a href="https://acme.com/cdn-cgi/l/email-protection#1f727e76736b70256c7e737a6c5f7e7c727a317c7072">Sales</a>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "press@acme.com", ASCII: "press@acme.com", Source: models.SourceCloudflare},
+					{Address: "sales@acme.com", ASCII: "sales@acme.com", Source: models.SourceCloudflare},
+				},
+			},
+		},
+		{
+			name: "success/Malformed Cloudflare payload is ignored",
+			args: args{
+				document: `<span data-cfemail="zz12"></span><a href="/cdn-cgi/l/email-protection#42">x</a>`,
+			},
+			expected: expected{
+				candidates: nil,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			candidates, err := ExtractEmailCandidates(strings.NewReader(tc.args.document))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected.candidates, candidates)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package scraper
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Registrable domains of hosting, site-builder, monitoring and mailing services whose
+// addresses end up in company pages: support contacts, theme credits and Sentry DSNs
+// such as https://0123abcd@o1.ingest.sentry.io/2
+var thirdPartyDomains = map[string]bool{
+	"sentry.io": true, "wixpress.com": true, "wix.com": true,
+	"parastorage.com": true, "squarespace.com": true, "shopify.com": true, "webflow.io": true,
+	"wordpress.com": true, "wpengine.com": true, "automattic.com": true, "jimdo.com": true,
+	"weebly.com": true, "godaddy.com": true, "secureserver.net": true, "hostgator.com": true,
+	"bluehost.com": true, "ionos.com": true, "ionos.de": true, "1und1.de": true, "strato.de": true,
+	"one.com": true, "hetzner.com": true, "ovh.com": true, "ovh.net": true, "cloudflare.com": true,
+	"hubspot.com": true, "mailchimp.com": true, "sendgrid.net": true, "mailgun.org": true,
+	"themeforest.net": true, "envato.com": true,
+}
+
+// Country-code top-level domains sold as generic ones, such as acme.io for a startup,
+// which say nothing about a company having a site for that country
+var genericCountryTLDs = map[string]bool{
+	"ac": true, "ai": true, "cc": true, "co": true, "fm": true, "gg": true, "im": true, "io": true,
+	"ly": true, "me": true, "sh": true, "so": true, "st": true, "to": true, "tv": true, "ws": true,
+}
+
+// Labels too short to tell a company's domains under other suffixes from someone else's
+const minBrandLabelLength = 3
+
+// domainLists holds the domains listed in the config, read once per ranking rather than
+// for every email
+type domainLists struct {
+	thirdParty map[string]bool
+	aliases    [][]string // registrable domains of each "scraper.domain_aliases" entry
+}
+
+func getDomainLists() domainLists {
+	lists := domainLists{thirdParty: make(map[string]bool)}
+
+	for _, thirdParty := range viper.GetStringSlice("scraper.third_party_domains") {
+		lists.thirdParty[strings.ToLower(strings.TrimSpace(thirdParty))] = true
+	}
+
+	for _, group := range viper.GetStringSlice("scraper.domain_aliases") {
+		var aliases []string
+
+		for _, alias := range strings.FieldsFunc(group, func(r rune) bool { return r == ',' || r == ' ' }) {
+			aliases = append(aliases, registrableDomain(alias))
+		}
+
+		lists.aliases = append(lists.aliases, aliases)
+	}
+
+	return lists
+}
+
+// registrableDomain returns the domain a host was registered under, using the public
+// suffix list: "shop.acme.co.uk" gives "acme.co.uk"
+func registrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+
+	return domain
+}
+
+// domainRelation tells how the domain of an email relates to the host of the company site.
+// The site's own domains are checked first, so the providers' own sites keep their addresses.
+func domainRelation(emailDomain, siteHost string, lists domainLists) models.DomainRelation {
+	emailDomain = strings.ToLower(emailDomain)
+	registrable := registrableDomain(emailDomain)
+
+	switch {
+	case siteHost == "":
+	case emailDomain == siteHost:
+		return models.DomainSame
+	case registrable == registrableDomain(siteHost):
+		return models.DomainRelated
+	}
+
+	switch {
+	case lists.isThirdParty(emailDomain):
+		return models.DomainThirdParty
+	case siteHost != "" && lists.isAlias(registrable, registrableDomain(siteHost)):
+		return models.DomainAlias
+	case genericMailDomains[registrable]:
+		return models.DomainWebmail
+	}
+
+	return models.DomainOther
+}
+
+// isAlias tells whether two registrable domains belong to the same company: the same
+// name under a country's suffix and .com or another country's, such as acme.de or
+// acme.co.uk for acme.com, or domains listed together in one entry of
+// "scraper.domain_aliases", e.g. "acmewidgets.com acme-group.com"
+func (l domainLists) isAlias(domain, siteDomain string) bool {
+	brand, suffix, _ := strings.Cut(domain, ".")
+	siteBrand, siteSuffix, _ := strings.Cut(siteDomain, ".")
+
+	if len(brand) >= minBrandLabelLength && brand == siteBrand && isBrandSuffixPair(suffix, siteSuffix) {
+		return true
+	}
+
+	for _, aliases := range l.aliases {
+		if slices.Contains(aliases, domain) && slices.Contains(aliases, siteDomain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBrandSuffixPair tells whether a name under both suffixes is likely one company's
+// sites for different countries: at least one is a country's suffix and the other is
+// .com or a country's, so acme.io or acme.net is not taken for acme.com
+func isBrandSuffixPair(suffix, siteSuffix string) bool {
+	country, siteCountry := isCountrySuffix(suffix), isCountrySuffix(siteSuffix)
+
+	return (country || siteCountry) && (country || suffix == "com") && (siteCountry || siteSuffix == "com")
+}
+
+// isCountrySuffix tells whether a public suffix ends in a country-code top-level domain
+// other than those sold as generic ones: "de" and "co.uk" do, "io" and "com" do not
+func isCountrySuffix(suffix string) bool {
+	tld := suffix[strings.LastIndex(suffix, ".")+1:]
+
+	return len(tld) == 2 && !genericCountryTLDs[tld]
+}
+
+// isThirdParty tells whether a domain, or a domain it is a subdomain of, is a known
+// service provider or listed in "scraper.third_party_domains". Suffixes are compared
+// rather than registrable domains, as some providers are on the public suffix list.
+func (l domainLists) isThirdParty(domain string) bool {
+	for ; domain != ""; _, domain, _ = strings.Cut(domain, ".") {
+		if thirdPartyDomains[domain] || l.thirdParty[domain] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{name: "Second-level domain", host: "www.acme.com", expected: "acme.com"},
+		{name: "Multi-label public suffix", host: "shop.acme.co.uk", expected: "acme.co.uk"},
+		{name: "Private suffix", host: "acme.github.io", expected: "acme.github.io"},
+		{name: "Punycode", host: "info.xn--bcher-kva.de", expected: "xn--bcher-kva.de"},
+		{name: "Suffix itself", host: "co.uk", expected: "co.uk"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, registrableDomain(tc.host))
+		})
+	}
+}
+
+func TestDomainRelation(t *testing.T) {
+	type args struct {
+		emailDomain string
+		siteHost    string
+		aliases     []string
+		thirdParty  []string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected models.DomainRelation
+	}{
+		{name: "Same host", args: args{emailDomain: "acme.co.uk", siteHost: "acme.co.uk"}, expected: models.DomainSame},
+		{name: "Subdomain of the site", args: args{emailDomain: "mail.acme.co.uk", siteHost: "acme.co.uk"}, expected: models.DomainRelated},
+		{name: "Site on a subdomain", args: args{emailDomain: "acme.co.uk", siteHost: "shop.acme.co.uk"}, expected: models.DomainRelated},
+		{name: "Other tenant of a shared suffix", args: args{emailDomain: "rival.github.io", siteHost: "acme.github.io"}, expected: models.DomainOther},
+		{name: "Same name under another suffix", args: args{emailDomain: "acme.de", siteHost: "acme.com"}, expected: models.DomainAlias},
+		{name: "Same name under co.uk", args: args{emailDomain: "acme.co.uk", siteHost: "acme.com"}, expected: models.DomainAlias},
+		{name: "Same name under two countries' suffixes", args: args{emailDomain: "acme.fr", siteHost: "acme.de"}, expected: models.DomainAlias},
+		{name: "Country suffix sold as generic", args: args{emailDomain: "acme.io", siteHost: "acme.com"}, expected: models.DomainOther},
+		{name: "Two generic suffixes", args: args{emailDomain: "acme.net", siteHost: "acme.com"}, expected: models.DomainOther},
+		{name: "Short names are not aliases", args: args{emailDomain: "ab.de", siteHost: "ab.com"}, expected: models.DomainOther},
+		{
+			name:     "Configured alias",
+			args:     args{emailDomain: "acme-group.com", siteHost: "shop.acmewidgets.com", aliases: []string{"acmewidgets.com, acme-group.com"}},
+			expected: models.DomainAlias,
+		},
+		{name: "Sentry DSN", args: args{emailDomain: "o12345.ingest.sentry.io", siteHost: "acme.com"}, expected: models.DomainThirdParty},
+		{name: "Site builder support", args: args{emailDomain: "wix.com", siteHost: "acme.com"}, expected: models.DomainThirdParty},
+		{name: "Provider's own site", args: args{emailDomain: "wix.com", siteHost: "wix.com"}, expected: models.DomainSame},
+		{
+			name:     "Configured third party",
+			args:     args{emailDomain: "agency.test", siteHost: "acme.com", thirdParty: []string{"Agency.test"}},
+			expected: models.DomainThirdParty,
+		},
+		{name: "Webmail", args: args{emailDomain: "gmail.com", siteHost: "acme.com"}, expected: models.DomainWebmail},
+		{name: "Unrelated", args: args{emailDomain: "agency.io", siteHost: "acme.com"}, expected: models.DomainOther},
+		{name: "Unknown site", args: args{emailDomain: "acme.com"}, expected: models.DomainOther},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("scraper.domain_aliases", tc.args.aliases)
+			viper.Set("scraper.third_party_domains", tc.args.thirdParty)
+
+			defer func() {
+				viper.Set("scraper.domain_aliases", nil)
+				viper.Set("scraper.third_party_domains", nil)
+			}()
+
+			assert.Equal(t, tc.expected, domainRelation(tc.args.emailDomain, tc.args.siteHost, getDomainLists()))
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package scraper
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Regular expression to find emails, whose local parts and domains may be Unicode (EAI
+// and IDN); the top-level domain is either letters or an IDNA "xn--" label
+var emailRegex = regexp.MustCompile(`[\p{L}\p{M}\p{N}._%+-]+@[\p{L}\p{M}\p{N}.-]+\.(?:xn--[a-zA-Z0-9-]+|\p{L}[\p{L}\p{M}]+)`)
+
+// Elements whose text content is never rendered to the visitor
+var hiddenTextElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+}
+
+// Attributes that commonly carry an email address as structured data
+var structuredEmailAttributes = map[string]bool{
+	"content":    true,
+	"title":      true,
+	"aria-label": true,
+	"data-email": true,
+	"data-mail":  true,
+}
+
+// Asset extensions that the email regex mistakes for top-level domains, e.g. logo@2x.png
+var assetExtensions = map[string]bool{
+	"png": true, "jpg": true, "jpeg": true, "gif": true, "svg": true, "webp": true,
+	"avif": true, "bmp": true, "ico": true, "tif": true, "tiff": true,
+	"css": true, "js": true, "json": true, "xml": true, "map": true,
+}
+
+// ExtractEmailCandidates tokenizes an HTML document and returns every email found in
+// its visible text, mailto: links and structured attributes, in document order.
+// Obfuscated and Cloudflare-protected addresses are decoded into the same list.
+func ExtractEmailCandidates(r io.Reader) ([]models.EmailCandidate, error) {
+	scanner := &pageScanner{}
+
+	err := scanner.scan(r)
+
+	return scanner.candidates, err
+}
+
+// pageLink is an anchor found on a page, used to discover further pages to crawl
+type pageLink struct {
+	href     string
+	text     string
+	inFooter bool
+}
+
+// openElement tracks an element that changes how its descendants' text is read
+type openElement struct {
+	tag    atom.Atom
+	nested int // same-tag descendants still open inside it
+}
+
+type pageScanner struct {
+	candidates  []models.EmailCandidate
+	phones      []models.PhoneCandidate
+	profiles    []models.SocialProfile // schema.org sameAs; linked profiles are read from links
+	addresses   []models.AddressCandidate
+	names       []models.PersonName
+	links       []pageLink
+	hiddenDepth int
+	footerDepth int
+	reversed    []openElement
+	anchor      *pageLink // link whose text is being collected
+
+	phoneContext  string                // previous visible text, for phone labels
+	itemProp      string                // schema.org property whose value is the next text
+	hCardProp     string                // hCard address part whose value is the next text
+	address       *models.PostalAddress // structured address being read
+	addressSource models.AddressSource
+	jsonLD        *strings.Builder // JSON-LD script being read
+	visibleText   strings.Builder  // text of the page, one line per text node, for addresses and names
+}
+
+func (s *pageScanner) scan(r io.Reader) error {
+	tokenizer := html.NewTokenizer(r)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			s.endLink()
+			s.finish()
+
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			return nil
+
+		case html.StartTagToken:
+			s.startTag(tokenizer.Token(), false)
+
+		case html.SelfClosingTagToken:
+			s.startTag(tokenizer.Token(), true)
+
+		case html.EndTagToken:
+			s.endTag(tokenizer.Token())
+
+		case html.TextToken:
+			if s.hiddenDepth == 0 {
+				s.text(string(tokenizer.Text()))
+			} else if s.jsonLD != nil {
+				s.jsonLD.Write(tokenizer.Text())
+			}
+
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+// scanText scans a document that has no markup, such as a text/plain response
+func (s *pageScanner) scanText(r io.Reader) error {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.text(string(text))
+	s.finish()
+
+	return nil
+}
+
+// finish closes the address being read, looks for addresses in the page text that were
+// not already marked up and collects the names of people
+func (s *pageScanner) finish() {
+	s.endAddress()
+	s.names = findPersonNames(s.visibleText.String())
+
+	marked := make(map[[2]string]bool)
+	for _, candidate := range s.addresses {
+		marked[[2]string{candidate.Address.Street, candidate.Address.PostalCode}] = true
+	}
+
+	for _, candidate := range findAddresses(s.visibleText.String()) {
+		if !marked[[2]string{candidate.Address.Street, candidate.Address.PostalCode}] {
+			s.addresses = append(s.addresses, candidate)
+		}
+	}
+}
+
+func (s *pageScanner) startTag(token html.Token, selfClosing bool) {
+	if !selfClosing {
+		if hiddenTextElements[token.DataAtom] {
+			s.hiddenDepth++
+		}
+
+		if token.DataAtom == atom.Footer {
+			s.footerDepth++
+		}
+
+		if token.DataAtom == atom.A {
+			s.startLink(token)
+		}
+
+		if top := len(s.reversed) - 1; top >= 0 && s.reversed[top].tag == token.DataAtom {
+			s.reversed[top].nested++
+		} else if isReversedText(token) {
+			s.reversed = append(s.reversed, openElement{tag: token.DataAtom})
+		}
+	}
+
+	s.candidates = append(s.candidates, emailsFromAttributes(token)...)
+	s.startPhoneTag(token)
+	s.startStructuredTag(token)
+	s.startHCardTag(token)
+}
+
+func (s *pageScanner) endTag(token html.Token) {
+	// an element that closes before any text leaves its property without a value
+	s.itemProp, s.hCardProp = "", ""
+
+	if hiddenTextElements[token.DataAtom] && s.hiddenDepth > 0 {
+		s.hiddenDepth--
+	}
+
+	if token.DataAtom == atom.Footer && s.footerDepth > 0 {
+		s.footerDepth--
+	}
+
+	if token.DataAtom == atom.A {
+		s.endLink()
+	}
+
+	if token.DataAtom == atom.Script {
+		s.endJSONLD()
+	}
+
+	if top := len(s.reversed) - 1; top >= 0 && s.reversed[top].tag == token.DataAtom {
+		if s.reversed[top].nested > 0 {
+			s.reversed[top].nested--
+		} else {
+			s.reversed = s.reversed[:top]
+		}
+	}
+}
+
+func (s *pageScanner) startLink(token html.Token) {
+	s.endLink()
+
+	for _, attr := range token.Attr {
+		if strings.EqualFold(attr.Key, "href") {
+			s.anchor = &pageLink{href: strings.TrimSpace(attr.Val), inFooter: s.footerDepth > 0}
+
+			return
+		}
+	}
+}
+
+func (s *pageScanner) endLink() {
+	if s.anchor == nil {
+		return
+	}
+
+	s.anchor.text = strings.Join(strings.Fields(s.anchor.text), " ")
+	s.links = append(s.links, *s.anchor)
+	s.anchor = nil
+}
+
+func (s *pageScanner) text(text string) {
+	if s.anchor != nil {
+		s.anchor.text += " " + text
+	}
+
+	if len(s.reversed) > 0 {
+		s.candidates = append(s.candidates, findEmails(reverseString(text), models.SourceObfuscated)...)
+
+		return
+	}
+
+	s.visibleText.WriteString(text)
+	s.visibleText.WriteByte('\n')
+	s.hCardText(text)
+
+	property := s.itemText(text)
+	if property != schemaTelephone {
+		s.phoneText(text)
+	}
+
+	var found []models.EmailCandidate
+
+	// the text of an email property is already a schema.org candidate
+	if property != schemaEmail {
+		found = findEmails(text, models.SourceText)
+		s.candidates = append(s.candidates, found...)
+	}
+
+	if deobfuscated := deobfuscateText(text); deobfuscated != text {
+		s.candidates = append(s.candidates, newCandidates(found, findEmails(deobfuscated, models.SourceObfuscated))...)
+	}
+}
+
+func emailsFromAttributes(token html.Token) []models.EmailCandidate {
+	var candidates []models.EmailCandidate
+
+	for _, attr := range token.Attr {
+		key := strings.ToLower(attr.Key)
+
+		switch {
+		case key == "href" && token.DataAtom == atom.A:
+			candidates = append(candidates, emailsFromMailto(attr.Val, models.SourceMailto)...)
+			candidates = append(candidates, emailsFromCloudflareLink(attr.Val)...)
+		case key == "data-cfemail":
+			candidates = append(candidates, findEmails(decodeCloudflareEmail(attr.Val), models.SourceCloudflare)...)
+		case structuredEmailAttributes[key]:
+			candidates = append(candidates, findEmails(attr.Val, models.SourceAttribute)...)
+		}
+	}
+
+	return candidates
+}
+
+// emailsFromMailto returns the recipients of a mailto: URL, e.g. mailto:a@x.com,b@x.com?subject=Hi
+func emailsFromMailto(href string, source models.EmailSource) []models.EmailCandidate {
+	href = strings.TrimSpace(href)
+	if len(href) < len("mailto:") || !strings.EqualFold(href[:len("mailto:")], "mailto:") {
+		return nil
+	}
+
+	recipients, _, _ := strings.Cut(href[len("mailto:"):], "?")
+	if unescaped, err := url.PathUnescape(recipients); err == nil {
+		recipients = unescaped
+	}
+
+	var candidates []models.EmailCandidate
+
+	for _, recipient := range strings.Split(recipients, ",") {
+		candidates = append(candidates, findEmails(recipient, source)...)
+	}
+
+	return candidates
+}
+
+func findEmails(text string, source models.EmailSource) []models.EmailCandidate {
+	var candidates []models.EmailCandidate
+
+	for _, match := range emailRegex.FindAllString(text, -1) {
+		match = cleanEmail(match)
+		if isAssetName(match) {
+			continue
+		}
+
+		display, ascii, ok := normalizeEmail(match)
+		if !ok || validateEmail(ascii) != nil {
+			continue
+		}
+
+		candidates = append(candidates, models.EmailCandidate{Address: display, ASCII: ascii, Source: source})
+	}
+
+	return candidates
+}
+
+func isAssetName(match string) bool {
+	ext := match[strings.LastIndex(match, ".")+1:]
+
+	return assetExtensions[strings.ToLower(ext)]
+}
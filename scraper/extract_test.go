@@ -0,0 +1,183 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestExtractEmailCandidates(t *testing.T) {
+	type args struct {
+		document string
+	}
+
+	type expected struct {
+		candidates []models.EmailCandidate
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Email in visible text",
+			args: args{
+				document: `<html><body><p>Contact us at info@acme.com</p></body></html>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Mailto link with several recipients and query",
+			args: args{
+				document: `<a href="MAILTO:sales@acme.com,support%40acme.com?subject=Hello">Write to us</a>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "sales@acme.com", ASCII: "sales@acme.com", Source: models.SourceMailto},
+					{Address: "support@acme.com", ASCII: "support@acme.com", Source: models.SourceMailto},
+				},
+			},
+		},
+		{
+			name: "success/Structured attributes",
+			args: args{
+				document: `<meta itemprop="email" content="hello@acme.com"><span data-email="team@acme.com"></span>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "hello@acme.com", ASCII: "hello@acme.com", Source: models.SourceAttribute},
+					{Address: "hello@acme.com", ASCII: "hello@acme.com", Source: models.SourceSchema},
+					{Address: "team@acme.com", ASCII: "team@acme.com", Source: models.SourceAttribute},
+				},
+			},
+		},
+		{
+			name: "success/Each occurrence tagged with its source in document order",
+			args: args{
+				document: `<a href="mailto:info@acme.com">info@acme.com</a>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceMailto},
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Scripts, styles and noscript are ignored",
+			args: args{
+				document: `<script>var dev = "dev@agency.io";</script>` +
+					`<style>.a{background:url("bg@2x.png")}</style>` +
+					`<noscript><img src="https://track.example/p?u=pixel@tracker.net"></noscript>` +
+					`<p>info@acme.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Image names are not emails",
+			args: args{
+				document: `<p>Download logo@2x.png or icon@3x.webp</p><img alt="banner@2x.JPG">`,
+			},
+			expected: expected{
+				candidates: nil,
+			},
+		},
+		{
+			name: "success/Entities in text are decoded",
+			args: args{
+				document: `<p>info&#64;acme.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Unicode domain gets an IDNA form",
+			args: args{
+				document: `<p>Schreiben Sie an info@Bücher.example</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@bücher.example", ASCII: "info@xn--bcher-kva.example", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Punycode domain is displayed in Unicode",
+			args: args{
+				document: `<a href="mailto:kontakt@xn--mnchen-3ya.xn--tckwe">Kontakt</a>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "kontakt@münchen.コム", ASCII: "kontakt@xn--mnchen-3ya.xn--tckwe", Source: models.SourceMailto},
+				},
+			},
+		},
+		{
+			name: "success/EAI address keeps its Unicode local part",
+			args: args{
+				document: `<a href="mailto:%D0%B8%D0%BD%D1%84%D0%BE@%D0%BF%D1%80%D0%B8%D0%BC%D0%B5%D1%80.%D1%80%D1%84">Почта</a>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "инфо@пример.рф", ASCII: "инфо@xn--e1afmkfd.xn--p1ai", Source: models.SourceMailto},
+				},
+			},
+		},
+		{
+			name: "success/URL-encoded mailto: in a query string",
+			args: args{
+				document: `<p>Share: https://acme.com/share?ref=mailto%3Ainfo@acme.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Addresses are cleaned and invalid or placeholder ones dropped",
+			args: args{
+				document: `<p>Mail info@acme.com. or %40sales@acme.com, not name@domain.c or user@example.com</p>`,
+			},
+			expected: expected{
+				candidates: []models.EmailCandidate{
+					{Address: "info@acme.com", ASCII: "info@acme.com", Source: models.SourceText},
+					{Address: "sales@acme.com", ASCII: "sales@acme.com", Source: models.SourceText},
+				},
+			},
+		},
+		{
+			name: "success/Non-mailto links are ignored",
+			args: args{
+				document: `<a href="https://user@acme.com/login">Login</a>`,
+			},
+			expected: expected{
+				candidates: nil,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			candidates, err := ExtractEmailCandidates(strings.NewReader(tc.args.document))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected.candidates, candidates)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Hosts Facebook pages link to that are never a company's own website
+var facebookOwnHosts = map[string]bool{
+	"fb.me": true, "fbcdn.net": true, "fbsbx.com": true, "messenger.com": true, "m.me": true,
+	"meta.com": true, "whatsapp.com": true, "wa.me": true, "oculus.com": true,
+	"apps.apple.com": true, "itunes.apple.com": true, "play.google.com": true,
+}
+
+// isFacebookURL tells whether a search result is a page on Facebook rather than the
+// company's own site
+func isFacebookURL(rawURL string) bool {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+
+	network, ok := socialNetwork(target.Hostname())
+
+	return ok && network == models.Facebook
+}
+
+// facebookWebsite returns the official website a company's Facebook page links to. The
+// page itself is read first and then its About tab, honoring robots.txt like any other
+// page; the website is the first outbound link whose text names its host. Other outbound
+// links may be anything the page shared, so they are not taken for the website.
+func (c *Crawler) facebookWebsite(ctx context.Context, pageURL string) (string, error) {
+	pages := []string{pageURL}
+	if about := facebookAboutURL(pageURL); about != "" {
+		pages = append(pages, about)
+	}
+
+	var lastErr error
+
+	for _, page := range pages {
+		scanner, finalURL, err := c.fetchPage(ctx, page)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		if website := facebookWebsiteFromLinks(scanner.links, finalURL); website != "" {
+			return website, nil
+		}
+	}
+
+	if lastErr != nil {
+		return "", fmt.Errorf("%w: %w", models.ErrNoWebsiteLinked, lastErr)
+	}
+
+	return "", models.ErrNoWebsiteLinked
+}
+
+// facebookAboutURL returns the About tab of a Facebook page, or "" when the URL already
+// points to a tab or is not a page
+func facebookAboutURL(pageURL string) string {
+	target, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.FieldsFunc(target.Path, func(r rune) bool { return r == '/' })
+	if len(segments) != 1 || strings.HasSuffix(strings.ToLower(segments[0]), ".php") {
+		return ""
+	}
+
+	target.Path = "/" + segments[0] + "/about"
+	target.RawQuery, target.Fragment = "", ""
+
+	return target.String()
+}
+
+// facebookWebsiteFromLinks picks the company website among the links of a Facebook page
+func facebookWebsiteFromLinks(links []pageLink, pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	for _, link := range links {
+		target, err := base.Parse(link.href)
+		if err != nil {
+			continue
+		}
+
+		website := outboundURL(target)
+		if website == "" {
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(link.text), hostOf(website)) {
+			return website
+		}
+	}
+
+	return ""
+}
+
+// outboundURL returns where a link on Facebook leads, unwrapping the l.facebook.com
+// redirector, or "" when it stays on Facebook, its services or another social network
+func outboundURL(target *url.URL) string {
+	if strings.EqualFold(target.Hostname(), "l.facebook.com") || strings.EqualFold(target.Hostname(), "lm.facebook.com") {
+		unwrapped, err := url.Parse(target.Query().Get("u"))
+		if err != nil {
+			return ""
+		}
+
+		target = unwrapped
+	}
+
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return ""
+	}
+
+	if _, ok := socialNetwork(target.Hostname()); ok {
+		return ""
+	}
+
+	host := strings.ToLower(target.Hostname())
+	for host != "" {
+		if facebookOwnHosts[host] {
+			return ""
+		}
+
+		_, host, _ = strings.Cut(host, ".")
+	}
+
+	// tracking parameters Facebook appends to outbound links
+	query := target.Query()
+	query.Del("fbclid")
+	target.RawQuery = query.Encode()
+	target.Fragment = ""
+
+	return target.String()
+}
@@ -0,0 +1,191 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// newFacebookClient serves saved Facebook pages by path from testdata/facebook and the
+// given company sites by host; robots.txt, sitemaps and other paths without a fixture
+// are not found
+func newFacebookClient(t *testing.T, fixtures map[string]string, sites map[string]string) *MockClient {
+	t.Helper()
+
+	pages := make(map[string]string)
+
+	for path, fixture := range fixtures {
+		body, err := os.ReadFile(filepath.Join("testdata", "facebook", fixture))
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+
+		pages[path] = string(body)
+	}
+
+	return &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			if page, ok := sites[req.URL.Host]; ok && req.URL.Path == "/" {
+				return mockHTTPResponse(http.StatusOK, page), nil
+			}
+
+			if page, ok := pages[req.URL.Path]; ok && req.URL.Host == "www.facebook.com" {
+				return mockHTTPResponse(http.StatusOK, page), nil
+			}
+
+			return mockHTTPResponse(http.StatusNotFound, ""), nil
+		},
+	}
+}
+
+func TestIsFacebookURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		expected bool
+	}{
+		{name: "Page", rawURL: "https://www.facebook.com/acmewidgets/", expected: true},
+		{name: "Mobile page", rawURL: "https://m.facebook.com/acmewidgets", expected: true},
+		{name: "Short domain", rawURL: "https://fb.com/acmewidgets", expected: true},
+		{name: "Company site mentioning Facebook", rawURL: "https://acme.test/facebook.com-campaign", expected: false},
+		{name: "Lookalike domain", rawURL: "https://notfacebook.com/acme", expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isFacebookURL(tc.rawURL))
+		})
+	}
+}
+
+func TestCrawlerFacebookWebsite(t *testing.T) {
+	fixtures := map[string]string{
+		"/acmewidgets":      "page.html",
+		"/acmebakery":       "page_without_website.html",
+		"/acmebakery/about": "about.html",
+		"/acmefans":         "page_without_website.html",
+		"/acmefans/about":   "page_without_website.html",
+		"/acmeoutlet":       "page_with_shared_link.html",
+		"/acmeoutlet/about": "page_without_website.html",
+	}
+
+	type expected struct {
+		website string
+		err     error
+	}
+
+	tests := []struct {
+		name     string
+		pageURL  string
+		expected expected
+	}{
+		{
+			name:     "success/Website named by its link text wins over other outbound links",
+			pageURL:  "https://www.facebook.com/acmewidgets",
+			expected: expected{website: "https://acme.test/"},
+		},
+		{
+			name:     "success/Website found on the About tab",
+			pageURL:  "https://www.facebook.com/acmebakery",
+			expected: expected{website: "http://www.acme-bakery.test/"},
+		},
+		{
+			name:     "error/Page links only to Facebook's own services",
+			pageURL:  "https://www.facebook.com/acmefans",
+			expected: expected{err: models.ErrNoWebsiteLinked},
+		},
+		{
+			name:     "error/Outbound link that does not name its site is not taken for the website",
+			pageURL:  "https://www.facebook.com/acmeoutlet",
+			expected: expected{err: models.ErrNoWebsiteLinked},
+		},
+		{
+			name:     "error/Page cannot be fetched",
+			pageURL:  "https://www.facebook.com/gone",
+			expected: expected{err: models.ErrNonOKStatus},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			crawler := NewCrawler(newFacebookClient(t, fixtures, nil), DefaultCrawlOptions())
+
+			website, err := crawler.facebookWebsite(context.Background(), tc.pageURL)
+
+			assert.Equal(t, tc.expected.website, website)
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, models.ErrNoWebsiteLinked)
+				assert.ErrorIs(t, err, tc.expected.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFacebookAboutURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageURL  string
+		expected string
+	}{
+		{name: "Page", pageURL: "https://www.facebook.com/acmewidgets/?ref=search", expected: "https://www.facebook.com/acmewidgets/about"},
+		{name: "Already a tab", pageURL: "https://www.facebook.com/acmewidgets/about", expected: ""},
+		{name: "Numeric profile", pageURL: "https://www.facebook.com/profile.php?id=100064", expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, facebookAboutURL(tc.pageURL))
+		})
+	}
+}
+
+func TestGetCompanyContactsFacebook(t *testing.T) {
+	client := newFacebookClient(t,
+		map[string]string{"/acmewidgets": "page.html", "/acmefans": "page_without_website.html"},
+		map[string]string{"acme.test": `<p>Write to info@acme.test</p>`},
+	)
+
+	company, err := GetCompanyContacts(context.Background(), client, EmailChecks{}, "https://www.facebook.com/acmewidgets", "Acme Widgets", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.test/", company.URL)
+	assert.Equal(t, "info@acme.test", company.Email)
+	assert.True(t, company.Emails[0].SameDomain)
+
+	_, err = GetCompanyContacts(context.Background(), client, EmailChecks{}, "https://www.facebook.com/acmefans", "Acme Fans", "")
+
+	assert.ErrorIs(t, err, models.ErrSkippingFacebookURL)
+	assert.ErrorIs(t, err, models.ErrNoWebsiteLinked)
+}
+
+func TestFacebookDisallowedByRobots(t *testing.T) {
+	// facebook.com serves "Disallow: /" to every crawler it has not allowlisted
+	client := newFacebookClient(t,
+		map[string]string{"/robots.txt": "robots.txt", "/acmewidgets": "page.html"},
+		map[string]string{"acme.test": `<p>Write to info@acme.test</p>`},
+	)
+
+	_, err := GetCompanyContacts(context.Background(), client, EmailChecks{}, "https://www.facebook.com/acmewidgets", "Acme Widgets", "")
+
+	assert.ErrorIs(t, err, models.ErrSkippingFacebookURL)
+	assert.ErrorIs(t, err, models.ErrDisallowedByRobots)
+
+	company, err := ResolveCompanyContacts(context.Background(), client, EmailChecks{},
+		[]string{"https://www.facebook.com/acmewidgets", "https://acme.test/"}, "Acme Widgets", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.test/", company.URL)
+	assert.Equal(t, "info@acme.test", company.Email)
+	assert.Len(t, company.Attempts, 2)
+	assert.ErrorIs(t, company.Attempts[0].Err, models.ErrDisallowedByRobots)
+	assert.NoError(t, company.Attempts[1].Err)
+}
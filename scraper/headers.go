@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// User-Agent sent to company sites unless configured otherwise
+const defaultUserAgent = "CompanyEmailScraper/1.0 (+https://github.com/Businge931/web-scrapper-go)"
+
+// robotsToken returns the product token of a User-Agent, e.g. "CompanyEmailScraper"
+// for "CompanyEmailScraper/1.0 (+https://...)"
+func robotsToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	token, _, _ = strings.Cut(token, "/")
+
+	return token
+}
+
+// userAgentFor returns the User-Agent for a host; with a pool configured, each host
+// keeps the agent it was first given so a site sees one consistent client
+func (c *Crawler) userAgentFor(host string) string {
+	if len(c.opts.UserAgents) == 0 {
+		return c.opts.UserAgent
+	}
+
+	c.sites.mu.Lock()
+	defer c.sites.mu.Unlock()
+
+	userAgent, ok := c.sites.userAgents[host]
+	if !ok {
+		userAgent = c.opts.UserAgents[rand.Intn(len(c.opts.UserAgents))] //nolint:gosec // not security sensitive
+		c.sites.userAgents[host] = userAgent
+	}
+
+	return userAgent
+}
+
+func (c *Crawler) setHeaders(req *http.Request) {
+	for name, value := range c.opts.Headers {
+		req.Header.Set(name, value)
+	}
+
+	req.Header.Set("User-Agent", c.userAgentFor(req.URL.Host))
+}
@@ -0,0 +1,183 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobotsToken(t *testing.T) {
+	tests := map[string]string{
+		"CompanyEmailScraper/1.0 (+https://example.com; ops@example.com)": "CompanyEmailScraper",
+		"AcmeBot": "AcmeBot",
+		"Mozilla/5.0 (X11; Linux x86_64) Gecko/20100101 Firefox/128.0": "Mozilla",
+	}
+
+	for userAgent, expected := range tests {
+		t.Run(userAgent, func(t *testing.T) {
+			assert.Equal(t, expected, robotsToken(userAgent))
+		})
+	}
+}
+
+func TestCrawlerRequestHeaders(t *testing.T) {
+	type args struct {
+		opts func(opts *CrawlOptions)
+	}
+
+	type expected struct {
+		userAgents     []string // any of these, the same one on every request
+		acceptLanguage string
+		custom         string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Default crawler identity and language",
+			args: args{
+				opts: func(_ *CrawlOptions) {},
+			},
+			expected: expected{
+				userAgents:     []string{defaultUserAgent},
+				acceptLanguage: "en-US,en;q=0.9",
+			},
+		},
+		{
+			name: "success/Configured user agent and headers",
+			args: args{
+				opts: func(opts *CrawlOptions) {
+					opts.UserAgent = "AcmeLeads/2.0 (+mailto:ops@acme.test)"
+					opts.Headers["Accept-Language"] = "de-DE,de;q=0.8"
+					opts.Headers["X-Request-Source"] = "lead-gen"
+				},
+			},
+			expected: expected{
+				userAgents:     []string{"AcmeLeads/2.0 (+mailto:ops@acme.test)"},
+				acceptLanguage: "de-DE,de;q=0.8",
+				custom:         "lead-gen",
+			},
+		},
+		{
+			name: "success/User agent pool is sticky per site",
+			args: args{
+				opts: func(opts *CrawlOptions) {
+					opts.UserAgents = []string{"AgentOne/1.0", "AgentTwo/1.0"}
+				},
+			},
+			expected: expected{
+				userAgents:     []string{"AgentOne/1.0", "AgentTwo/1.0"},
+				acceptLanguage: "en-US,en;q=0.9",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				headers []http.Header
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				headers = append(headers, r.Header.Clone())
+				mu.Unlock()
+
+				_, err := w.Write([]byte(`<a href="/contact">Contact</a>`))
+				if err != nil {
+					t.Error("failed to write mock response")
+				}
+			}))
+			defer server.Close()
+
+			opts := DefaultCrawlOptions()
+			tc.args.opts(&opts)
+
+			_, err := NewCrawler(server.Client(), opts).Crawl(context.Background(), server.URL)
+			assert.NoError(t, err)
+
+			assert.NotEmpty(t, headers)
+			assert.Contains(t, tc.expected.userAgents, headers[0].Get("User-Agent"))
+
+			for _, header := range headers {
+				assert.Equal(t, headers[0].Get("User-Agent"), header.Get("User-Agent"))
+				assert.Equal(t, tc.expected.acceptLanguage, header.Get("Accept-Language"))
+				assert.Equal(t, tc.expected.custom, header.Get("X-Request-Source"))
+				assert.Contains(t, header.Get("Accept"), "text/html")
+			}
+		})
+	}
+}
+
+func TestGetCrawlOptionsHeaders(t *testing.T) {
+	// keys as viper reads them from config.yaml
+	viper.Set("crawler.headers", map[string]string{"accept-language": "de-DE,de;q=0.8", "x-request-source": "lead-gen"})
+	defer viper.Set("crawler.headers", nil)
+
+	opts := getCrawlOptions()
+
+	assert.Equal(t, map[string]string{
+		"Accept":           "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"Accept-Language":  "de-DE,de;q=0.8",
+		"X-Request-Source": "lead-gen",
+	}, opts.Headers)
+}
+
+func TestNewHTTPClientCookieJar(t *testing.T) {
+	// A consent wall: pages redirect to /consent until its cookie is presented
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/consent" {
+			http.SetCookie(w, &http.Cookie{Name: "consent", Value: "yes", Path: "/"})
+			http.Redirect(w, r, "/", http.StatusFound)
+
+			return
+		}
+
+		if _, err := r.Cookie("consent"); err != nil {
+			http.Redirect(w, r, "/consent", http.StatusFound)
+
+			return
+		}
+
+		_, err := w.Write([]byte("info@acme.test"))
+		if err != nil {
+			t.Error("failed to write mock response")
+		}
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		cookies bool
+		wantErr bool
+	}{
+		"With cookie jar":    {cookies: true},
+		"Without cookie jar": {cookies: false, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := DefaultHTTPClientOptions()
+			opts.Cookies = tc.cookies
+
+			resp, err := NewHTTPClient(opts).Get(server.URL)
+			if tc.wantErr {
+				assert.Error(t, err, "expected the redirect loop to be stopped")
+
+				return
+			}
+
+			assert.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
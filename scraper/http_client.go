@@ -0,0 +1,139 @@
+package scraper
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/publicsuffix"
+)
+
+// HTTPClientOptions tunes the transport shared by search and company site requests.
+type HTTPClientOptions struct {
+	Timeout               time.Duration // whole request, including reading the body
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	KeepAlive             time.Duration // TCP keep-alive period; negative disables keep-alive
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int  // 0 means no limit
+	Cookies               bool // keep cookies for the client's lifetime, e.g. to get past consent redirects
+
+	// Proxies carries requests through a proxy pool; nil uses the HTTP_PROXY environment
+	Proxies *ProxyPool
+}
+
+func DefaultHTTPClientOptions() HTTPClientOptions {
+	return HTTPClientOptions{
+		Timeout:               10 * time.Second,
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		KeepAlive:             30 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   2,
+		MaxConnsPerHost:       4,
+		Cookies:               true,
+	}
+}
+
+func getHTTPClientOptions() HTTPClientOptions {
+	opts := DefaultHTTPClientOptions()
+
+	durations := map[string]*time.Duration{
+		"http.timeout":                 &opts.Timeout,
+		"http.dial_timeout":            &opts.DialTimeout,
+		"http.tls_handshake_timeout":   &opts.TLSHandshakeTimeout,
+		"http.response_header_timeout": &opts.ResponseHeaderTimeout,
+		"http.idle_conn_timeout":       &opts.IdleConnTimeout,
+		"http.keep_alive":              &opts.KeepAlive,
+	}
+	for key, value := range durations {
+		if viper.IsSet(key) {
+			*value = viper.GetDuration(key)
+		}
+	}
+
+	ints := map[string]*int{
+		"http.max_idle_conns":          &opts.MaxIdleConns,
+		"http.max_idle_conns_per_host": &opts.MaxIdleConnsPerHost,
+		"http.max_conns_per_host":      &opts.MaxConnsPerHost,
+	}
+	for key, value := range ints {
+		if viper.IsSet(key) {
+			*value = viper.GetInt(key)
+		}
+	}
+
+	if viper.IsSet("http.cookies") {
+		opts.Cookies = viper.GetBool("http.cookies")
+	}
+
+	return opts
+}
+
+// NewHTTPClient builds a client whose transport applies the given timeouts,
+// connection limits and proxies. Each client gets its own cookie jar, so cookies
+// last for one run.
+func NewHTTPClient(opts HTTPClientOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		DisableKeepAlives:     opts.KeepAlive < 0,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}
+
+	if opts.Cookies {
+		client.Jar = newCookieJar()
+	}
+
+	if opts.Proxies != nil && len(opts.Proxies.proxies) > 0 {
+		client.Transport = newProxyTransport(opts.Proxies, transport)
+	}
+
+	return client
+}
+
+func newCookieJar() http.CookieJar {
+	// cookiejar.New only fails on invalid options
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+
+	return jar
+}
+
+// NewHTTPClientFromConfig builds the client for a request class from the "http" config
+// section and the class's "proxy" section.
+func NewHTTPClientFromConfig(class RequestClass) (*http.Client, error) {
+	opts := getHTTPClientOptions()
+
+	proxies, err := NewProxyPool(getProxyOptions(class))
+	if err != nil {
+		return nil, fmt.Errorf("%s proxies: %w", class, err)
+	}
+
+	opts.Proxies = proxies
+
+	return NewHTTPClient(opts), nil
+}
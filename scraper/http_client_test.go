@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClientFromConfig(t *testing.T) {
+	type dependencies struct {
+		config map[string]any
+	}
+
+	type expected struct {
+		timeout             time.Duration
+		maxConnsPerHost     int
+		maxIdleConnsPerHost int
+		disableKeepAlives   bool
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		expected     expected
+	}{
+		{
+			name: "success/Defaults",
+			dependencies: dependencies{
+				config: map[string]any{},
+			},
+			expected: expected{
+				timeout:             10 * time.Second,
+				maxConnsPerHost:     4,
+				maxIdleConnsPerHost: 2,
+			},
+		},
+		{
+			name: "success/Overrides from config",
+			dependencies: dependencies{
+				config: map[string]any{
+					"http.timeout":            "30s",
+					"http.max_conns_per_host": 1,
+					"http.keep_alive":         "-1s",
+				},
+			},
+			expected: expected{
+				timeout:             30 * time.Second,
+				maxConnsPerHost:     1,
+				maxIdleConnsPerHost: 2,
+				disableKeepAlives:   true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.dependencies.config {
+				viper.Set(key, value)
+			}
+
+			defer viper.Reset()
+
+			client, err := NewHTTPClientFromConfig(SearchRequests)
+			assert.NoError(t, err)
+
+			transport, ok := client.Transport.(*http.Transport)
+			assert.True(t, ok)
+
+			assert.Equal(t, tc.expected.timeout, client.Timeout)
+			assert.Equal(t, tc.expected.maxConnsPerHost, transport.MaxConnsPerHost)
+			assert.Equal(t, tc.expected.maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tc.expected.disableKeepAlives, transport.DisableKeepAlives)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiHost returns the lower-case IDNA (punycode) form of a host name, e.g.
+// "Bücher.example" becomes "xn--bcher-kva.example". Hosts that are not valid
+// IDNA names, such as IP addresses, are only lower-cased.
+func asciiHost(host string) string {
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+
+	return strings.ToLower(host)
+}
+
+// asciiURL rewrites the host of rawURL to its IDNA form, keeping any port, so the
+// same page is not queued twice under its Unicode and punycode names
+func asciiURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return rawURL
+	}
+
+	host, port, err := net.SplitHostPort(parsedURL.Host)
+	if err != nil {
+		parsedURL.Host = asciiHost(parsedURL.Host)
+	} else {
+		parsedURL.Host = net.JoinHostPort(asciiHost(host), port)
+	}
+
+	return parsedURL.String()
+}
+
+// normalizeEmail returns the display and ASCII forms of an address. The display form has
+// a Unicode domain ("info@bücher.example") and the ASCII form an IDNA one
+// ("info@xn--bcher-kva.example"). The local part is NFC-normalized and kept as is in both:
+// an EAI address such as "δοκιμή@παράδειγμα.δοκιμή" has no ASCII equivalent and can
+// only be delivered by an SMTPUTF8 server (RFC 6531). ok is false when the domain is
+// not a valid IDNA name.
+func normalizeEmail(address string) (display, ascii string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 {
+		return "", "", false
+	}
+
+	local := norm.NFC.String(address[:at])
+
+	asciiDomain, err := idna.Lookup.ToASCII(address[at+1:])
+	if err != nil {
+		return "", "", false
+	}
+
+	displayDomain, err := idna.Display.ToUnicode(asciiDomain)
+	if err != nil {
+		return "", "", false
+	}
+
+	return local + "@" + displayDomain, local + "@" + asciiDomain, true
+}
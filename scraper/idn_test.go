@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	type expected struct {
+		display string
+		ascii   string
+		ok      bool
+	}
+
+	tests := []struct {
+		name     string
+		address  string
+		expected expected
+	}{
+		{
+			name:     "success/ASCII address is unchanged apart from the domain case",
+			address:  "Info@ACME.com",
+			expected: expected{display: "Info@acme.com", ascii: "Info@acme.com", ok: true},
+		},
+		{
+			name:     "success/Unicode domain",
+			address:  "info@bücher.example",
+			expected: expected{display: "info@bücher.example", ascii: "info@xn--bcher-kva.example", ok: true},
+		},
+		{
+			name:     "success/Punycode domain",
+			address:  "info@XN--BCHER-KVA.example",
+			expected: expected{display: "info@bücher.example", ascii: "info@xn--bcher-kva.example", ok: true},
+		},
+		{
+			name:     "success/EAI local part is NFC-normalized",
+			address:  "josé@acme.com",
+			expected: expected{display: "josé@acme.com", ascii: "josé@acme.com", ok: true},
+		},
+		{
+			name:    "error/Invalid punycode label",
+			address: "info@xn--a.example",
+		},
+		{
+			name:    "error/Missing local part",
+			address: "@acme.com",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			display, ascii, ok := normalizeEmail(tc.address)
+
+			assert.Equal(t, tc.expected.ok, ok)
+			assert.Equal(t, tc.expected.display, display)
+			assert.Equal(t, tc.expected.ascii, ascii)
+		})
+	}
+}
+
+func TestASCIIURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		expected string
+	}{
+		{name: "Unicode host", rawURL: "https://Bücher.example/kontakt", expected: "https://xn--bcher-kva.example/kontakt"},
+		{name: "Unicode host with port", rawURL: "http://bücher.example:8080/", expected: "http://xn--bcher-kva.example:8080/"},
+		{name: "Unicode path is escaped", rawURL: "https://пример.рф/о-нас", expected: "https://xn--e1afmkfd.xn--p1ai/%D0%BE-%D0%BD%D0%B0%D1%81"},
+		{name: "Percent-encoded host", rawURL: "https://b%C3%BCcher.example/", expected: "https://xn--bcher-kva.example/"},
+		{name: "IP address is kept", rawURL: "http://127.0.0.1:8080/contact", expected: "http://127.0.0.1:8080/contact"},
+		{name: "Relative reference is kept", rawURL: "/contact", expected: "/contact"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, asciiURL(tc.rawURL))
+		})
+	}
+}
+
+func TestDiscoverContactPagesIDN(t *testing.T) {
+	links := []pageLink{
+		{href: "https://bücher.example/kontakt", text: "Kontakt"},
+		{href: "https://www.xn--bcher-kva.example/impressum", text: "Impressum"},
+	}
+
+	urls := discoverContactPages(links, "https://xn--bcher-kva.example/", hostOf("https://Bücher.example"), nil)
+
+	assert.Equal(t, []string{
+		"https://xn--bcher-kva.example/kontakt",
+		"https://www.xn--bcher-kva.example/impressum",
+	}, urls)
+}
@@ -0,0 +1,168 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// OutputFormat decides how the batch run writes each company.
+type OutputFormat string
+
+const (
+	// OutputText writes "company : email : phones" lines.
+	OutputText OutputFormat = "text"
+	// OutputJSONLines writes one CompanyRecord per line, with every detail found.
+	OutputJSONLines OutputFormat = "jsonl"
+)
+
+// ParseOutputFormat validates a format name, defaulting to OutputText when empty.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case "":
+		return OutputText, nil
+	case OutputText, OutputJSONLines:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %s", models.ErrUnknownOutputFormat, name)
+	}
+}
+
+// GetOutputFormat returns the format configured in "output.format".
+func GetOutputFormat() (OutputFormat, error) {
+	return ParseOutputFormat(viper.GetString("output.format"))
+}
+
+// CompanyRecord is the JSON form of a company's contacts; Error says why the lookup
+// failed, with the contacts found until then.
+type CompanyRecord struct {
+	Name         string            `json:"name"`
+	URL          string            `json:"url,omitempty"`
+	Email        string            `json:"email,omitempty"`
+	Emails       []EmailRecord     `json:"emails,omitempty"`
+	Phones       []string          `json:"phones,omitempty"`
+	Social       map[string]string `json:"social,omitempty"` // profile URL by network
+	Address      *AddressRecord    `json:"address,omitempty"`
+	EmailPattern *PatternRecord    `json:"email_pattern,omitempty"`
+	Attempts     []AttemptRecord   `json:"attempts,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// EmailRecord is the JSON form of a ranked email.
+type EmailRecord struct {
+	Address        string  `json:"address"`
+	Score          float64 `json:"score"`
+	SameDomain     bool    `json:"same_domain"`
+	Relation       string  `json:"relation,omitempty"`
+	Deliverability string  `json:"deliverability,omitempty"`
+	Mailbox        string  `json:"mailbox,omitempty"`
+}
+
+// AddressRecord is the JSON form of a postal address.
+type AddressRecord struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+// PatternRecord is the JSON form of an inferred email pattern.
+type PatternRecord struct {
+	Pattern    string   `json:"pattern"`
+	Domain     string   `json:"domain"`
+	Confidence float64  `json:"confidence"`
+	Examples   []string `json:"examples"`
+}
+
+// AttemptRecord is the JSON form of a search result tried for a company.
+type AttemptRecord struct {
+	URL   string `json:"url"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewCompanyRecord converts the result of a lookup, and the error it failed with if any,
+// to its JSON form.
+func NewCompanyRecord(company models.CompanyResult, err error) CompanyRecord {
+	record := CompanyRecord{
+		Name:   company.Name,
+		URL:    company.URL,
+		Email:  company.Email,
+		Phones: company.Phones,
+		Social: socialRecord(company.Social),
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	for _, email := range company.Emails {
+		record.Emails = append(record.Emails, EmailRecord{
+			Address:        email.Address,
+			Score:          email.Score,
+			SameDomain:     email.SameDomain,
+			Relation:       string(email.Relation),
+			Deliverability: string(email.Deliverability),
+			Mailbox:        string(email.Mailbox),
+		})
+	}
+
+	if address := company.Address; address != (models.PostalAddress{}) {
+		record.Address = &AddressRecord{
+			Street: address.Street, City: address.City, Region: address.Region,
+			PostalCode: address.PostalCode, Country: address.Country,
+		}
+	}
+
+	if pattern := company.EmailPattern; pattern.Pattern != "" {
+		record.EmailPattern = &PatternRecord{
+			Pattern: string(pattern.Pattern), Domain: pattern.Domain,
+			Confidence: pattern.Confidence, Examples: pattern.Examples,
+		}
+	}
+
+	for _, attempt := range company.Attempts {
+		attemptRecord := AttemptRecord{URL: attempt.URL}
+		if attempt.Err != nil {
+			attemptRecord.Error = attempt.Err.Error()
+		}
+
+		record.Attempts = append(record.Attempts, attemptRecord)
+	}
+
+	return record
+}
+
+// socialRecord lists the profiles found by network, leaving out networks without one
+func socialRecord(social models.SocialProfiles) map[string]string {
+	profiles := make(map[string]string)
+
+	for network, profileURL := range map[models.SocialNetwork]string{
+		models.LinkedIn: social.LinkedIn, models.Facebook: social.Facebook, models.X: social.X,
+		models.Instagram: social.Instagram, models.YouTube: social.YouTube, models.GitHub: social.GitHub,
+	} {
+		if profileURL != "" {
+			profiles[string(network)] = profileURL
+		}
+	}
+
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	return profiles
+}
+
+// WriteCompanyRecord writes the result of a lookup as one JSON line
+func WriteCompanyRecord(w io.Writer, company models.CompanyResult, err error) error {
+	if encodeErr := json.NewEncoder(w).Encode(NewCompanyRecord(company, err)); encodeErr != nil {
+		return fmt.Errorf("%w: %w", models.ErrWriteFileFailed, encodeErr)
+	}
+
+	return nil
+}
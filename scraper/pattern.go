@@ -0,0 +1,245 @@
+package scraper
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// A person's name written out in page text: "Jane Doe", "Jane M. Doe", "Jean-Luc Picard"
+var personNameRegex = regexp.MustCompile(`(\p{Lu}\p{Ll}+(?:-\p{Lu}\p{Ll}+)?)(?: \p{Lu}\.)? (\p{Lu}[\p{Ll}'’]+(?:-\p{Lu}\p{Ll}+)?)`)
+
+// Patterns in the order they are tried, so the most specific one explains an address
+var emailPatterns = []models.EmailPattern{
+	models.PatternFirstDotLast, models.PatternFirstUnderscoreLast, models.PatternFirstHyphenLast,
+	models.PatternFirstLast, models.PatternInitialDotLast, models.PatternInitialLast,
+	models.PatternFirstInitial, models.PatternFirst, models.PatternLast,
+}
+
+// Separators of a two-part local part, for addresses whose owner is not named on the page
+var separatorPatterns = []struct {
+	separator string
+	pattern   models.EmailPattern
+}{
+	{".", models.PatternFirstDotLast},
+	{"_", models.PatternFirstUnderscoreLast},
+	{"-", models.PatternFirstHyphenLast},
+}
+
+// Words of role addresses that are not covered by the scoring roles, so that
+// customer.service is not taken for a person
+var roleWords = map[string]bool{
+	"customer": true, "service": true, "support": true, "help": true, "press": true,
+	"media": true, "jobs": true, "careers": true, "hr": true, "marketing": true,
+	"billing": true, "accounts": true, "accounting": true, "admin": true, "orders": true,
+	"booking": true, "reception": true, "events": true, "partners": true, "newsletter": true,
+}
+
+// Doubt left in a pattern after each address following it
+const patternEvidenceBase = 0.5
+
+// findPersonNames returns what look like the first and last names of people in the text
+// of a page, such as a team page or an imprint. Titles such as "Sales Manager" are
+// returned too; only a name an address is built from counts.
+func findPersonNames(text string) []models.PersonName {
+	var names []models.PersonName
+
+	for _, match := range personNameRegex.FindAllStringSubmatch(text, -1) {
+		names = append(names, models.PersonName{First: match[1], Last: match[2]})
+	}
+
+	return names
+}
+
+// InferEmailPattern infers the pattern a company builds its people's addresses with from
+// the named addresses among the ranked emails on its own domains. An address is matched
+// against the names of people found on the site, and a local part such as jane.doe that
+// no name explains is still read as first.last. The result is empty when no address on
+// the company's domains belongs to a person.
+func InferEmailPattern(ranked []models.RankedEmail, names []models.PersonName) models.InferredPattern {
+	type tally struct {
+		domain   string
+		pattern  models.EmailPattern
+		examples []string
+	}
+
+	var tallies []*tally
+
+	named := make(map[string]int)
+
+	for _, email := range ranked {
+		local, domain, _ := strings.Cut(strings.ToLower(email.Address), "@")
+		if !email.SameDomain || isRoleLocalPart(local) {
+			continue
+		}
+
+		pattern := matchEmailPattern(local, names)
+		if pattern == "" {
+			continue
+		}
+
+		named[domain]++
+
+		var found *tally
+
+		for _, t := range tallies {
+			if t.domain == domain && t.pattern == pattern {
+				found = t
+			}
+		}
+
+		if found == nil {
+			found = &tally{domain: domain, pattern: pattern}
+			tallies = append(tallies, found)
+		}
+
+		found.examples = append(found.examples, email.Address)
+	}
+
+	var best *tally
+
+	for _, t := range tallies {
+		if best == nil || len(t.examples) > len(best.examples) {
+			best = t
+		}
+	}
+
+	if best == nil {
+		return models.InferredPattern{}
+	}
+
+	share := float64(len(best.examples)) / float64(named[best.domain])
+	evidence := 1 - math.Pow(patternEvidenceBase, float64(len(best.examples)))
+
+	return models.InferredPattern{
+		Pattern:    best.pattern,
+		Domain:     best.domain,
+		Confidence: math.Round(share*evidence*100) / 100,
+		Examples:   best.examples,
+	}
+}
+
+// matchEmailPattern returns the pattern a local part was built with from the name of
+// one of the people, or from its shape when none of them explains it
+func matchEmailPattern(local string, names []models.PersonName) models.EmailPattern {
+	for _, name := range names {
+		first, last := foldName(name.First), foldName(name.Last)
+		if first == "" || last == "" {
+			continue
+		}
+
+		for _, pattern := range emailPatterns {
+			if renderEmailPattern(pattern, first, last) == local {
+				return pattern
+			}
+		}
+	}
+
+	for _, separated := range separatorPatterns {
+		first, last, ok := strings.Cut(local, separated.separator)
+		if ok && len(first) > 1 && len(last) > 1 && isLetters(first) && isLetters(last) {
+			return separated.pattern
+		}
+	}
+
+	return ""
+}
+
+// EmailForName builds the address of a person, such as a contact found elsewhere, with
+// an inferred pattern; it is empty when there is no pattern or the name lacks a part the
+// pattern needs.
+func EmailForName(pattern models.InferredPattern, name string) string {
+	parts := strings.Fields(name)
+	if pattern.Pattern == "" || len(parts) == 0 {
+		return ""
+	}
+
+	first, last := foldName(parts[0]), foldName(parts[len(parts)-1])
+	if len(parts) == 1 {
+		last = ""
+	}
+
+	local := renderEmailPattern(pattern.Pattern, first, last)
+	if local == "" {
+		return ""
+	}
+
+	return local + "@" + pattern.Domain
+}
+
+// renderEmailPattern builds a local part from folded names; it is empty when a name the
+// pattern needs is missing
+func renderEmailPattern(pattern models.EmailPattern, first, last string) string {
+	if first == "" || (last == "" && pattern != models.PatternFirst) {
+		return ""
+	}
+
+	initial := string([]rune(first)[:1])
+
+	switch pattern {
+	case models.PatternFirstDotLast:
+		return first + "." + last
+	case models.PatternFirstUnderscoreLast:
+		return first + "_" + last
+	case models.PatternFirstHyphenLast:
+		return first + "-" + last
+	case models.PatternFirstLast:
+		return first + last
+	case models.PatternInitialDotLast:
+		return initial + "." + last
+	case models.PatternInitialLast:
+		return initial + last
+	case models.PatternFirstInitial:
+		return first + string([]rune(last)[:1])
+	case models.PatternFirst:
+		return first
+	case models.PatternLast:
+		return last
+	}
+
+	return ""
+}
+
+// foldName lower-cases a name and drops accents, hyphens and apostrophes, the way names
+// are written in addresses: "José" gives "jose" and "Jean-Luc" gives "jeanluc"
+func foldName(name string) string {
+	var folded strings.Builder
+
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		if unicode.IsLetter(r) {
+			folded.WriteRune(r)
+		}
+	}
+
+	return folded.String()
+}
+
+// isRoleLocalPart tells whether a local part addresses a function rather than a person
+func isRoleLocalPart(local string) bool {
+	if preferredRoles[local] || avoidedRoles[local] {
+		return true
+	}
+
+	for _, word := range strings.FieldsFunc(local, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+		if roleWords[word] || preferredRoles[word] || avoidedRoles[word] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+
+	return true
+}
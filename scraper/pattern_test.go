@@ -0,0 +1,128 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestInferEmailPattern(t *testing.T) {
+	names := []models.PersonName{{First: "Jane", Last: "Doe"}, {First: "José", Last: "García"}, {First: "Max", Last: "Müller"}}
+
+	own := func(addresses ...string) []models.RankedEmail {
+		ranked := make([]models.RankedEmail, 0, len(addresses))
+		for _, address := range addresses {
+			ranked = append(ranked, models.RankedEmail{Address: address, SameDomain: true})
+		}
+
+		return ranked
+	}
+
+	type args struct {
+		ranked []models.RankedEmail
+		names  []models.PersonName
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected models.InferredPattern
+	}{
+		{
+			name: "Initial and last name matched to the people on the site",
+			args: args{ranked: own("info@acme.test", "jdoe@acme.test", "jgarcia@acme.test", "mmuller@acme.test"), names: names},
+			expected: models.InferredPattern{
+				Pattern: models.PatternInitialLast, Domain: "acme.test", Confidence: 0.88,
+				Examples: []string{"jdoe@acme.test", "jgarcia@acme.test", "mmuller@acme.test"},
+			},
+		},
+		{
+			name: "First name only",
+			args: args{ranked: own("jane@acme.test"), names: names},
+			expected: models.InferredPattern{
+				Pattern: models.PatternFirst, Domain: "acme.test", Confidence: 0.5, Examples: []string{"jane@acme.test"},
+			},
+		},
+		{
+			name: "Separated names read without the people being named",
+			args: args{ranked: own("anna.berg@acme.test", "tom.lind@acme.test")},
+			expected: models.InferredPattern{
+				Pattern: models.PatternFirstDotLast, Domain: "acme.test", Confidence: 0.75,
+				Examples: []string{"anna.berg@acme.test", "tom.lind@acme.test"},
+			},
+		},
+		{
+			name: "Mixed patterns lower the confidence",
+			args: args{ranked: own("jane.doe@acme.test", "jose.garcia@acme.test", "max@acme.test"), names: names},
+			expected: models.InferredPattern{
+				Pattern: models.PatternFirstDotLast, Domain: "acme.test", Confidence: 0.5,
+				Examples: []string{"jane.doe@acme.test", "jose.garcia@acme.test"},
+			},
+		},
+		{
+			name: "Role addresses are not people",
+			args: args{ranked: own("customer.service@acme.test", "sales@acme.test", "no-reply@acme.test"), names: names},
+		},
+		{
+			name: "Addresses on other domains are ignored",
+			args: args{ranked: []models.RankedEmail{{Address: "jane.doe@agency.test"}}, names: names},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, InferEmailPattern(tc.args.ranked, tc.args.names))
+		})
+	}
+}
+
+func TestEmailForName(t *testing.T) {
+	type args struct {
+		pattern models.EmailPattern
+		name    string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected string
+	}{
+		{name: "First and last name", args: args{pattern: models.PatternFirstDotLast, name: "Jane Doe"}, expected: "jane.doe@acme.test"},
+		{name: "Middle names are dropped", args: args{pattern: models.PatternInitialLast, name: "Jane Mary Doe"}, expected: "jdoe@acme.test"},
+		{name: "Accents and hyphens are dropped", args: args{pattern: models.PatternFirstLast, name: "Jean-Luc García"}, expected: "jeanlucgarcia@acme.test"},
+		{name: "First name only", args: args{pattern: models.PatternFirst, name: "Jane"}, expected: "jane@acme.test"},
+		{name: "Last name missing", args: args{pattern: models.PatternFirstDotLast, name: "Jane"}, expected: ""},
+		{name: "No pattern", args: args{name: "Jane Doe"}, expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern := models.InferredPattern{Pattern: tc.args.pattern, Domain: "acme.test"}
+
+			assert.Equal(t, tc.expected, EmailForName(pattern, tc.args.name))
+		})
+	}
+}
+
+func TestFindPersonNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []models.PersonName
+	}{
+		{
+			name:     "Names on a team page",
+			text:     "Our team\nJane Doe\nChief executive\nJean-Luc Picard, Captain\nAnna M. Berg",
+			expected: []models.PersonName{{First: "Jane", Last: "Doe"}, {First: "Jean-Luc", Last: "Picard"}, {First: "Anna", Last: "Berg"}},
+		},
+		{name: "Names do not run across lines", text: "Jane\nDoe"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, findPersonNames(tc.text))
+		})
+	}
+}
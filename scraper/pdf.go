@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Gap between two glyphs of a line, as a fraction of the font size, read as a space.
+// Kerning in TJ arrays stays well below it.
+const pdfWordGap = 0.15
+
+// pdfText extracts the text of a PDF document for the email and phone extraction, one
+// line per line of text, reading at most maxPages pages (0 reads them all). The body is
+// read whole, since a PDF is indexed from its end; the response size limit bounds it.
+func pdfText(body io.Reader, maxPages int) (text io.Reader, err error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// the parser panics on some malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = nil, fmt.Errorf("%w: %v", models.ErrUnreadablePDF, r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrUnreadablePDF, err)
+	}
+
+	pages := reader.NumPage()
+	if maxPages > 0 {
+		pages = min(pages, maxPages)
+	}
+
+	var extracted strings.Builder
+
+	for i := 1; i <= pages; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		writePDFPage(&extracted, page.Content().Text)
+	}
+
+	return strings.NewReader(extracted.String()), nil
+}
+
+// writePDFPage lays out the glyphs of a page in the order they are drawn: a glyph above
+// or below the previous one starts a new line, and a gap between two glyphs of a line
+// wider than pdfWordGap is read as a space. The fragments of a kerned TJ array thus stay
+// one word.
+func writePDFPage(extracted *strings.Builder, glyphs []pdf.Text) {
+	var previous *pdf.Text
+
+	for i := range glyphs {
+		glyph := &glyphs[i]
+		if glyph.S == "\n" { // marks the end of a TJ array, not a line
+			continue
+		}
+
+		switch {
+		case previous == nil:
+		case math.Abs(glyph.Y-previous.Y) > glyph.FontSize/2:
+			extracted.WriteByte('\n')
+		case glyph.X-(previous.X+previous.W) > glyph.FontSize*pdfWordGap && glyph.S != " " && previous.S != " ":
+			extracted.WriteByte(' ')
+		}
+
+		extracted.WriteString(glyph.S)
+		previous = glyph
+	}
+
+	if previous != nil {
+		extracted.WriteByte('\n')
+	}
+}
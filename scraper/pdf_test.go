@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestPDFText(t *testing.T) {
+	brochure, err := os.ReadFile(filepath.Join("testdata", "pdf", "brochure.pdf"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	kerned, err := os.ReadFile(filepath.Join("testdata", "pdf", "kerned.pdf"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	type args struct {
+		body     string
+		maxPages int
+	}
+
+	type expected struct {
+		lines []string
+		err   error
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Every page",
+			args: args{body: string(brochure)},
+			expected: expected{lines: []string{
+				"Acme Widgets GmbH",
+				"Product brochure 2024",
+				"Contact us",
+				"Sales: sales@acme.test",
+				"Tel: +49 30 1234567",
+				"Press enquiries: press@acme.test",
+			}},
+		},
+		{
+			name: "success/Page limit",
+			args: args{body: string(brochure), maxPages: 1},
+			expected: expected{lines: []string{
+				"Acme Widgets GmbH",
+				"Product brochure 2024",
+			}},
+		},
+		{
+			name: "success/Kerned TJ arrays stay whole words",
+			args: args{body: string(kerned)},
+			expected: expected{lines: []string{
+				"Contact us",
+				"Mail: info@acme.test",
+				"Tel: +49 30 1234567",
+			}},
+		},
+		{
+			name:     "error/Not a PDF",
+			args:     args{body: "<html></html>"},
+			expected: expected{err: models.ErrUnreadablePDF},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			text, err := pdfText(strings.NewReader(tc.args.body), tc.args.maxPages)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			extracted, err := io.ReadAll(text)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected.lines, strings.Split(strings.TrimSuffix(string(extracted), "\n"), "\n"))
+		})
+	}
+}
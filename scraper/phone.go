@@ -0,0 +1,269 @@
+package scraper
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+const (
+	// E.164 numbers have at most 15 digits including the country code
+	maxPhoneDigits = 15
+	minPhoneDigits = 7
+	// how far before a number a "Tel"/"Phone" label is looked for
+	phoneLabelWindow = 32
+)
+
+var (
+	// digits with the separators people format phone numbers with
+	phoneRegex = regexp.MustCompile(`(?:\+|\(\+?)?\d[\d\s().\-/]{5,}\d`)
+
+	// labels that announce a phone number, as whole words so "Hotel", "Teller" or "Called"
+	// are not labels; a closer "fax" or "telefax" label rules the number out
+	phoneLabelRegex = regexp.MustCompile(
+		`(?i)\b(?:tele?fax|fax|tel|telephone|telefon|phone|call|fon|mobile?|cell(?:phone)?|hotline)\b|☎|📞`,
+	)
+
+	// dates and ISO timestamps have the shape of a number with separators
+	dateLikeRegex = regexp.MustCompile(`^\d{4}[-./]\d{1,2}[-./]\d{1,2}$|^\d{1,2}[-./]\d{1,2}[-./]\d{2,4}$`)
+
+	// "(0)" between the country code and the number, as in +49 (0)30 1234567
+	trunkInParensRegex = regexp.MustCompile(`\(\s*0\s*\)`)
+
+	// a trailing extension, as in "ext. 12", "x12" or "#12"
+	phoneExtensionRegex = regexp.MustCompile(`(?i)\s*(?:ext\.?|extension|x|#)\s*\d{1,6}\s*$`)
+)
+
+// dialingPlan is the country calling code of a country and the trunk prefix dialed before
+// national numbers, which is dropped in international format
+type dialingPlan struct {
+	code  string
+	trunk string
+}
+
+// Dialing plans of the countries whose numbers can be written without a country code
+var dialingPlans = map[string]dialingPlan{
+	"US": {code: "1", trunk: "1"}, "CA": {code: "1", trunk: "1"},
+	"GB": {code: "44", trunk: "0"}, "IE": {code: "353", trunk: "0"},
+	"DE": {code: "49", trunk: "0"}, "AT": {code: "43", trunk: "0"}, "CH": {code: "41", trunk: "0"},
+	"FR": {code: "33", trunk: "0"}, "BE": {code: "32", trunk: "0"}, "NL": {code: "31", trunk: "0"},
+	"LU": {code: "352"}, "ES": {code: "34"}, "PT": {code: "351"}, "IT": {code: "39"}, // Italy keeps the 0
+	"SE": {code: "46", trunk: "0"}, "NO": {code: "47"}, "DK": {code: "45"}, "FI": {code: "358", trunk: "0"},
+	"PL": {code: "48"}, "CZ": {code: "420"}, "SK": {code: "421", trunk: "0"}, "HU": {code: "36", trunk: "06"},
+	"RO": {code: "40", trunk: "0"}, "GR": {code: "30"}, "TR": {code: "90", trunk: "0"},
+	"RU": {code: "7", trunk: "8"}, "UA": {code: "380", trunk: "0"},
+	"IN": {code: "91", trunk: "0"}, "CN": {code: "86", trunk: "0"}, "JP": {code: "81", trunk: "0"},
+	"KR": {code: "82", trunk: "0"}, "SG": {code: "65"}, "HK": {code: "852"},
+	"AU": {code: "61", trunk: "0"}, "NZ": {code: "64", trunk: "0"},
+	"ZA": {code: "27", trunk: "0"}, "NG": {code: "234", trunk: "0"}, "KE": {code: "254", trunk: "0"},
+	"UG": {code: "256", trunk: "0"}, "AE": {code: "971", trunk: "0"}, "IL": {code: "972", trunk: "0"},
+	"BR": {code: "55", trunk: "0"}, "MX": {code: "52"}, "AR": {code: "54", trunk: "0"},
+}
+
+// Country-code top-level domains that differ from the ISO 3166 code
+var ccTLDCountries = map[string]string{
+	"uk": "GB",
+}
+
+// phoneCountry returns the country national numbers on a company site are assumed to
+// belong to: the one given with the company, the configured "phones.default_country",
+// or else the site's ccTLD
+func phoneCountry(siteURL, given string) string {
+	if country := strings.ToUpper(strings.TrimSpace(given)); country != "" {
+		return country
+	}
+
+	if country := strings.ToUpper(strings.TrimSpace(viper.GetString("phones.default_country"))); country != "" {
+		return country
+	}
+
+	host := hostOf(siteURL)
+	tld := strings.ToLower(host[strings.LastIndex(host, ".")+1:])
+
+	if country, ok := ccTLDCountries[tld]; ok {
+		return country
+	}
+
+	if _, ok := dialingPlans[strings.ToUpper(tld)]; ok {
+		return strings.ToUpper(tld)
+	}
+
+	return ""
+}
+
+// NormalizePhones converts phone candidates to E.164, dropping duplicates and numbers
+// that cannot be normalized. National numbers need the country they belong to.
+// Numbers published as schema.org data come first, as the company declared them itself.
+func NormalizePhones(candidates []models.PhoneCandidate, country string) []string {
+	seen := make(map[string]bool)
+
+	ordered := make([]models.PhoneCandidate, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Source == models.PhoneFromSchema && ordered[j].Source != models.PhoneFromSchema
+	})
+
+	var phones []string
+
+	for _, candidate := range ordered {
+		phone, ok := normalizePhone(candidate.Raw, country)
+		if !ok || seen[phone] {
+			continue
+		}
+
+		seen[phone] = true
+		phones = append(phones, phone)
+	}
+
+	return phones
+}
+
+// normalizePhone returns the E.164 form of a number written in international format
+// ("+49 (0)30 1234567", "0049 30 1234567") or, given its country, in national format
+func normalizePhone(raw, country string) (string, bool) {
+	raw = phoneExtensionRegex.ReplaceAllString(strings.TrimSpace(raw), "")
+
+	if strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "(+") {
+		return internationalPhone(phoneDigits(trunkInParensRegex.ReplaceAllString(raw, "")))
+	}
+
+	digits := phoneDigits(raw)
+	if strings.HasPrefix(digits, "00") {
+		return internationalPhone(digits[2:])
+	}
+
+	return nationalPhone(digits, country)
+}
+
+// phoneDigits drops everything but the digits of a number
+func phoneDigits(number string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, number)
+}
+
+// internationalPhone returns the E.164 form of the digits after the international prefix,
+// which start with the country code
+func internationalPhone(digits string) (string, bool) {
+	if len(digits) < minPhoneDigits+1 || len(digits) > maxPhoneDigits || digits[0] == '0' {
+		return "", false
+	}
+
+	return "+" + digits, true
+}
+
+// nationalPhone returns the E.164 form of the digits of a number dialed within country,
+// dropping the country's trunk prefix
+func nationalPhone(digits, country string) (string, bool) {
+	plan, ok := dialingPlans[strings.ToUpper(country)]
+	if !ok {
+		return "", false
+	}
+
+	if plan.code == "1" {
+		// North American numbers are always ten digits after the optional 1
+		digits = strings.TrimPrefix(digits, plan.trunk)
+		if len(digits) != 10 {
+			return "", false
+		}
+
+		return "+1" + digits, true
+	}
+
+	if plan.trunk != "" {
+		digits = strings.TrimPrefix(digits, plan.trunk)
+	}
+
+	if len(digits) < minPhoneDigits-2 || len(plan.code)+len(digits) > maxPhoneDigits {
+		return "", false
+	}
+
+	return "+" + plan.code + digits, true
+}
+
+// findPhones returns the numbers in a text that are written in international format
+// or announced by a phone label in the preceding text; numbers labeled fax are skipped
+func findPhones(text, preceding string, source models.PhoneSource) []models.PhoneCandidate {
+	var candidates []models.PhoneCandidate
+
+	for _, match := range phoneRegex.FindAllStringIndex(text, -1) {
+		number := strings.TrimSpace(text[match[0]:match[1]])
+
+		digits := len(phoneDigits(number))
+		if digits < minPhoneDigits || digits > maxPhoneDigits || dateLikeRegex.MatchString(number) {
+			continue
+		}
+
+		label := lastPhoneLabel(preceding + " " + text[:match[0]])
+		if strings.HasSuffix(label, "fax") || (label == "" && !strings.HasPrefix(strings.TrimLeft(number, "("), "+")) {
+			continue
+		}
+
+		candidates = append(candidates, models.PhoneCandidate{Raw: number, Source: source})
+	}
+
+	return candidates
+}
+
+// lastPhoneLabel returns the label closest before a number, lower-cased, if any is near
+func lastPhoneLabel(before string) string {
+	if runes := []rune(before); len(runes) > phoneLabelWindow {
+		before = string(runes[len(runes)-phoneLabelWindow:])
+	}
+
+	labels := phoneLabelRegex.FindAllString(before, -1)
+	if len(labels) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(labels[len(labels)-1])
+}
+
+// phonesFromTel returns the number of a tel: URL, e.g. tel:+49-30-1234567;ext=12
+func phonesFromTel(href string) []models.PhoneCandidate {
+	href = strings.TrimSpace(href)
+	if len(href) < len("tel:") || !strings.EqualFold(href[:len("tel:")], "tel:") {
+		return nil
+	}
+
+	number, _, _ := strings.Cut(href[len("tel:"):], ";")
+	if unescaped, err := url.PathUnescape(number); err == nil {
+		number = unescaped
+	}
+
+	return []models.PhoneCandidate{{Raw: number, Source: models.PhoneFromTel}}
+}
+
+// startPhoneTag collects the numbers in tel: links
+func (s *pageScanner) startPhoneTag(token html.Token) {
+	if token.DataAtom != atom.A {
+		return
+	}
+
+	for _, attr := range token.Attr {
+		if strings.EqualFold(attr.Key, "href") {
+			s.phones = append(s.phones, phonesFromTel(attr.Val)...)
+		}
+	}
+}
+
+// phoneText collects the numbers in visible text; the previous text is kept as
+// context so a label in one element can announce a number in the next
+func (s *pageScanner) phoneText(text string) {
+	s.phones = append(s.phones, findPhones(text, s.phoneContext, models.PhoneFromText)...)
+
+	if trimmed := strings.TrimSpace(text); trimmed != "" {
+		s.phoneContext = trimmed
+	}
+}
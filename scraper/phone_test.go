@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	type args struct {
+		raw     string
+		country string
+	}
+
+	type expected struct {
+		phone string
+		ok    bool
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{name: "success/International with trunk in parentheses", args: args{raw: "+49 (0)30 123 456-7"}, expected: expected{phone: "+49301234567", ok: true}},
+		{name: "success/International with 00 prefix", args: args{raw: "0044 20 7946 0958"}, expected: expected{phone: "+442079460958", ok: true}},
+		{name: "success/Extension is dropped", args: args{raw: "+1 (415) 555-0100 ext. 12"}, expected: expected{phone: "+14155550100", ok: true}},
+		{name: "success/German national number", args: args{raw: "030 / 123 45 67", country: "de"}, expected: expected{phone: "+49301234567", ok: true}},
+		{name: "success/US national number with leading 1", args: args{raw: "1-415-555-0100", country: "US"}, expected: expected{phone: "+14155550100", ok: true}},
+		{name: "success/Italian numbers keep the leading 0", args: args{raw: "06 1234 5678", country: "IT"}, expected: expected{phone: "+390612345678", ok: true}},
+		{name: "success/Ugandan mobile number", args: args{raw: "0772 123456", country: "UG"}, expected: expected{phone: "+256772123456", ok: true}},
+		{name: "error/National number without a country", args: args{raw: "030 1234567"}},
+		{name: "error/US number with the wrong length", args: args{raw: "555-0100", country: "US"}},
+		{name: "error/Too many digits", args: args{raw: "+49 30 1234567890123"}},
+		{name: "error/Unknown country", args: args{raw: "030 1234567", country: "XX"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			phone, ok := normalizePhone(tc.args.raw, tc.args.country)
+
+			assert.Equal(t, tc.expected.ok, ok)
+			assert.Equal(t, tc.expected.phone, phone)
+		})
+	}
+}
+
+func TestFindPhones(t *testing.T) {
+	type args struct {
+		text      string
+		preceding string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected []string
+	}{
+		{name: "Labeled national number", args: args{text: "Tel.: 030 1234567"}, expected: []string{"030 1234567"}},
+		{name: "Label in the previous element", args: args{text: "(415) 555-0100", preceding: "Call us"}, expected: []string{"(415) 555-0100"}},
+		{name: "International number needs no label", args: args{text: "Reach us on +256 772 123456 today"}, expected: []string{"+256 772 123456"}},
+		{name: "Fax numbers are skipped", args: args{text: "Telefon: 030 1234567, Telefax: 030 1234568"}, expected: []string{"030 1234567"}},
+		{name: "Unlabeled national number", args: args{text: "Order 030 1234567 shipped"}},
+		{name: "Labels inside words are not labels", args: args{text: "Hotel Adlon, opened 1907, rooms 1234567"}},
+		{name: "Intel is not a label", args: args{text: "Intel part 030 1234567"}},
+		{name: "Information is not a label", args: args{text: "More information: 030 1234567"}},
+		{name: "Recall is not a label", args: args{text: "Product recall 030 1234567"}},
+		{name: "Excellent is not a label", args: args{text: "An excellent year: 030 1234567"}},
+		{name: "Teller is not a label", args: args{text: "Teller window 030 1234567"}},
+		{name: "Called is not a label", args: args{text: "Called off in 030 1234567"}},
+		{name: "Telemetry is not a label", args: args{text: "Telemetry unit 030 1234567"}},
+		{name: "Whole-word labels", args: args{text: "Telephone: 030 1234567, Mobile: 0170 1234567"}, expected: []string{"030 1234567", "0170 1234567"}},
+		{name: "Label symbol", args: args{text: "☎ 030 1234567"}, expected: []string{"030 1234567"}},
+		{name: "Dates are not numbers", args: args{text: "Phone hours changed on 2024-01-15"}},
+		{name: "Short digit runs are not numbers", args: args{text: "Phone: 12-34"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var numbers []string
+			for _, candidate := range findPhones(tc.args.text, tc.args.preceding, models.PhoneFromText) {
+				numbers = append(numbers, candidate.Raw)
+			}
+
+			assert.Equal(t, tc.expected, numbers)
+		})
+	}
+}
+
+func TestPageScannerPhones(t *testing.T) {
+	document := `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Organization",
+ "contactPoint": [{"@type": "ContactPoint", "telephone": "+49-30-1234567"}]}
+</script>
+<script>var fake = "Tel: 030 9999999";</script>
+</head><body>
+<a href="tel:+49%2030%207654321;ext=5">Call</a>
+<div itemscope itemtype="https://schema.org/LocalBusiness">
+  <span itemprop="telephone">030 5555555</span>
+  <meta itemprop="telephone" content="+49 30 6666666">
+</div>
+<p><strong>Phone</strong> <span>030 1111111</span></p>
+<p>Fax: 030 2222222</p>
+</body></html>`
+
+	scanner := &pageScanner{}
+	assert.NoError(t, scanner.scan(strings.NewReader(document)))
+
+	assert.Equal(t, []models.PhoneCandidate{
+		{Raw: "+49-30-1234567", Source: models.PhoneFromSchema},
+		{Raw: "+49 30 7654321", Source: models.PhoneFromTel},
+		{Raw: "030 5555555", Source: models.PhoneFromSchema},
+		{Raw: "+49 30 6666666", Source: models.PhoneFromSchema},
+		{Raw: "030 1111111", Source: models.PhoneFromText},
+	}, scanner.phones)
+
+	assert.Equal(t, []string{
+		"+49301234567", "+49305555555", "+49306666666", "+49307654321", "+49301111111",
+	}, NormalizePhones(scanner.phones, "DE"))
+}
+
+func TestPhoneCountry(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultCountry string
+		siteURL        string
+		given          string
+		expected       string
+	}{
+		{name: "Country-code TLD", siteURL: "https://www.acme.de/", expected: "DE"},
+		{name: "TLD that differs from the ISO code", siteURL: "https://acme.co.uk/", expected: "GB"},
+		{name: "Generic TLD", siteURL: "https://acme.com/", expected: ""},
+		{name: "Configured country wins", defaultCountry: "ug", siteURL: "https://acme.de/", expected: "UG"},
+		{name: "Country given with the company wins", defaultCountry: "UG", siteURL: "https://acme.de/", given: "at", expected: "AT"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("phones.default_country", tc.defaultCountry)
+			defer viper.Set("phones.default_country", "")
+
+			assert.Equal(t, tc.expected, phoneCountry(tc.siteURL, tc.given))
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// RequestClass separates outbound traffic so each class can be routed differently.
+type RequestClass string
+
+const (
+	// SearchRequests are calls to the search API.
+	SearchRequests RequestClass = "search"
+	// FetchRequests are page, robots.txt and sitemap requests to company sites.
+	FetchRequests RequestClass = "fetch"
+)
+
+// ProxySelection decides which proxy of a pool carries a request.
+type ProxySelection string
+
+const (
+	// ProxyRoundRobin rotates through the healthy proxies on every request.
+	ProxyRoundRobin ProxySelection = "round-robin"
+	// ProxySticky keeps all requests to one host on the same proxy while it stays healthy.
+	ProxySticky ProxySelection = "sticky"
+)
+
+// ProxyOptions configures the proxies used by one request class.
+type ProxyOptions struct {
+	URLs        []string // http://, https:// or socks5:// with optional user:password@
+	Selection   ProxySelection
+	MaxFailures int           // consecutive failures before a proxy is benched
+	Cooldown    time.Duration // how long a benched proxy is skipped
+}
+
+func DefaultProxyOptions() ProxyOptions {
+	return ProxyOptions{
+		Selection:   ProxyRoundRobin,
+		MaxFailures: 3,
+		Cooldown:    time.Minute,
+	}
+}
+
+func getProxyOptions(class RequestClass) ProxyOptions {
+	opts := DefaultProxyOptions()
+	prefix := "proxy." + string(class) + "."
+
+	opts.URLs = viper.GetStringSlice(prefix + "urls")
+
+	if selection := viper.GetString(prefix + "selection"); selection != "" {
+		opts.Selection = ProxySelection(strings.ToLower(selection))
+	}
+
+	if viper.IsSet(prefix + "max_failures") {
+		opts.MaxFailures = viper.GetInt(prefix + "max_failures")
+	}
+
+	if viper.IsSet(prefix + "cooldown") {
+		opts.Cooldown = viper.GetDuration(prefix + "cooldown")
+	}
+
+	return opts
+}
+
+type proxyState struct {
+	url          *url.URL
+	failures     int
+	benchedUntil time.Time
+}
+
+// ProxyPool picks a proxy per request and benches proxies that keep failing.
+type ProxyPool struct {
+	opts ProxyOptions
+
+	mu      sync.Mutex
+	proxies []*proxyState
+	next    int
+	sticky  map[string]*proxyState // by request host
+}
+
+func NewProxyPool(opts ProxyOptions) (*ProxyPool, error) {
+	if opts.Selection != ProxyRoundRobin && opts.Selection != ProxySticky {
+		return nil, fmt.Errorf("%w: unknown selection %q", models.ErrInvalidProxy, opts.Selection)
+	}
+
+	pool := &ProxyPool{opts: opts, sticky: make(map[string]*proxyState)}
+
+	for _, rawURL := range opts.URLs {
+		proxyURL, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrInvalidProxy, err)
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("%w: unsupported scheme %q", models.ErrInvalidProxy, proxyURL.Scheme)
+		}
+
+		if proxyURL.Host == "" {
+			return nil, fmt.Errorf("%w: missing host in %q", models.ErrInvalidProxy, proxyURL.Redacted())
+		}
+
+		pool.proxies = append(pool.proxies, &proxyState{url: proxyURL})
+	}
+
+	return pool, nil
+}
+
+// pick returns the proxy for a request to host, preferring healthy proxies. When every
+// proxy is benched the one coming back soonest is used rather than failing the request.
+func (p *ProxyPool) pick(host string) *proxyState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	if p.opts.Selection == ProxySticky {
+		if proxy, ok := p.sticky[host]; ok && !proxy.benchedUntil.After(now) {
+			return proxy
+		}
+	}
+
+	var proxy *proxyState
+
+	for i := range p.proxies {
+		candidate := p.proxies[(p.next+i)%len(p.proxies)]
+		if !candidate.benchedUntil.After(now) {
+			proxy = candidate
+			p.next = (p.next + i + 1) % len(p.proxies)
+
+			break
+		}
+	}
+
+	if proxy == nil {
+		proxy = p.proxies[0]
+		for _, candidate := range p.proxies[1:] {
+			if candidate.benchedUntil.Before(proxy.benchedUntil) {
+				proxy = candidate
+			}
+		}
+	}
+
+	if p.opts.Selection == ProxySticky {
+		p.sticky[host] = proxy
+	}
+
+	return proxy
+}
+
+// report records the outcome of a request sent through proxy
+func (p *ProxyPool) report(proxy *proxyState, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !failed {
+		proxy.failures = 0
+
+		return
+	}
+
+	proxy.failures++
+	if proxy.failures >= max(p.opts.MaxFailures, 1) {
+		proxy.failures = 0
+		proxy.benchedUntil = time.Now().Add(p.opts.Cooldown)
+	}
+}
+
+// healthy returns the proxies that are not currently benched, with credentials redacted
+func (p *ProxyPool) healthy() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var healthy []string
+
+	for _, proxy := range p.proxies {
+		if !proxy.benchedUntil.After(time.Now()) {
+			healthy = append(healthy, proxy.url.Redacted())
+		}
+	}
+
+	return healthy
+}
+
+type proxyContextKey struct{}
+
+// proxyAttempt is the proxy picked for one request. connectFailed is set when the proxy
+// answers the CONNECT of an https request with anything but 200, which the transport
+// reports as a plain error.
+type proxyAttempt struct {
+	proxy         *proxyState
+	connectFailed atomic.Bool
+}
+
+// proxyTransport routes each request through a proxy picked from the pool and feeds
+// the outcome back into the pool's health tracking.
+type proxyTransport struct {
+	pool *ProxyPool
+	base *http.Transport
+}
+
+func newProxyTransport(pool *ProxyPool, base *http.Transport) *proxyTransport {
+	base.Proxy = func(req *http.Request) (*url.URL, error) {
+		if attempt, ok := req.Context().Value(proxyContextKey{}).(*proxyAttempt); ok {
+			return attempt.proxy.url, nil
+		}
+
+		return nil, nil //nolint:nilnil // no proxy means a direct connection
+	}
+
+	base.OnProxyConnectResponse = func(ctx context.Context, _ *url.URL, _ *http.Request, resp *http.Response) error {
+		if attempt, ok := ctx.Value(proxyContextKey{}).(*proxyAttempt); ok && resp.StatusCode != http.StatusOK {
+			attempt.connectFailed.Store(true)
+		}
+
+		return nil
+	}
+
+	return &proxyTransport{pool: pool, base: base}
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempt := &proxyAttempt{proxy: t.pool.pick(req.URL.Host)}
+
+	resp, err := t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyContextKey{}, attempt)))
+	if req.Context().Err() == nil {
+		t.pool.report(attempt.proxy, attempt.connectFailed.Load() || proxyFailed(resp, err))
+	}
+
+	return resp, err
+}
+
+// proxyFailed reports whether a request failed because of its proxy rather than the site:
+// the proxy could not be reached, its SOCKS handshake failed or it asked for credentials.
+// Errors from the site behind a working proxy do not count against it.
+func proxyFailed(resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode == http.StatusProxyAuthRequired
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks"))
+}
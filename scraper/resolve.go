@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// Search results tried per company when "scraper.max_sites" is not set
+const defaultMaxSites = 3
+
+func getMaxSites() int {
+	if maxSites := viper.GetInt("scraper.max_sites"); maxSites > 0 {
+		return maxSites
+	}
+
+	return defaultMaxSites
+}
+
+// LookupCompany searches for a company and resolves its contacts from the search
+// results, as the batch run does for each name in the input file. The country, which
+// may be empty, is the hint for the company's national phone numbers.
+func LookupCompany(
+	ctx context.Context, searchClient, fetchClient HTTPClient, checks EmailChecks, companyName, country string,
+) (models.CompanyResult, error) {
+	candidateURLs, err := GetSearchResultURLs(ctx, searchClient, companyName)
+	if err != nil {
+		return models.CompanyResult{Name: companyName}, err
+	}
+
+	return ResolveCompanyContacts(ctx, fetchClient, checks, candidateURLs, companyName, country)
+}
+
+// ResolveCompanyContacts tries the search results for a company in order until one
+// yields an email, up to "scraper.max_sites" sites. Results on a site already tried are
+// passed over without counting. Every site tried is recorded in the result's Attempts
+// with the reason it was passed over.
+//
+// When no site yields an email, the first one that had phone numbers is returned
+// without an error, and its attempt is recorded without one; otherwise the call
+// fails with models.ErrNoUsableSite wrapping the reason the last site failed.
+func ResolveCompanyContacts(
+	ctx context.Context, client HTTPClient, checks EmailChecks, candidateURLs []string, companyName, country string,
+) (models.CompanyResult, error) {
+	maxSites := getMaxSites()
+	tried := make(map[string]bool)
+
+	var (
+		attempts []models.SiteAttempt
+		partial  *models.CompanyResult
+		kept     int // attempt the partial result was taken from
+		lastErr  error
+	)
+
+	for _, candidateURL := range candidateURLs {
+		if len(attempts) >= maxSites {
+			break
+		}
+
+		if host := hostOf(candidateURL); host != "" {
+			if tried[host] {
+				continue
+			}
+
+			tried[host] = true
+		}
+
+		company, err := GetCompanyContacts(ctx, client, checks, candidateURL, companyName, country)
+		if err == nil && company.Email == "" {
+			// phone numbers alone do not end the search
+			err = fmt.Errorf("%w: %s", models.ErrNoEmailFound, companyName)
+
+			if partial == nil {
+				partial, kept = &company, len(attempts)
+			}
+		}
+
+		// a Facebook page may have led to a site that later results point to again
+		tried[hostOf(company.URL)] = true
+
+		attempts = append(attempts, models.SiteAttempt{URL: candidateURL, Err: err})
+
+		if err == nil {
+			company.Attempts = attempts
+
+			return company, nil
+		}
+
+		lastErr = err
+	}
+
+	if partial != nil {
+		attempts[kept].Err = nil
+		partial.Attempts = attempts
+
+		return *partial, nil
+	}
+
+	company := models.CompanyResult{Name: companyName, Attempts: attempts}
+
+	if lastErr == nil {
+		return company, fmt.Errorf("%w: %s", models.ErrNoResultsFound, companyName)
+	}
+
+	return company, fmt.Errorf("%w: %s: %w", models.ErrNoUsableSite, companyName, lastErr)
+}
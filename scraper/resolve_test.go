@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestResolveCompanyContacts(t *testing.T) {
+	sites := map[string]string{
+		"noemail.test": `<p>Welcome to our site</p>`,
+		"phones.test":  `<p>Tel: +49 30 1234567</p>`,
+		"good.test":    `<p>Write to info@good.test</p>`,
+	}
+
+	client := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "www.facebook.com" && req.URL.Path == "/fans" {
+				return mockHTTPResponse(http.StatusOK, `<a href="https://m.me/fans">Message</a>`), nil
+			}
+
+			if page, ok := sites[req.URL.Host]; ok && req.URL.Path == "/" {
+				return mockHTTPResponse(http.StatusOK, page), nil
+			}
+
+			if req.URL.Host == "bad.test" && req.URL.Path == "/" {
+				return mockHTTPResponse(http.StatusInternalServerError, ""), nil
+			}
+
+			return mockHTTPResponse(http.StatusNotFound, ""), nil
+		},
+	}
+
+	type args struct {
+		candidateURLs []string
+		maxSites      int
+	}
+
+	type expected struct {
+		email       string
+		url         string
+		phones      []string
+		attempts    []string
+		attemptErrs []error
+		err         error
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Walks the results until one yields an email",
+			args: args{candidateURLs: []string{"https://bad.test/", "https://noemail.test/", "https://good.test/"}},
+			expected: expected{
+				email:       "info@good.test",
+				url:         "https://good.test/",
+				attempts:    []string{"https://bad.test/", "https://noemail.test/", "https://good.test/"},
+				attemptErrs: []error{models.ErrNonOKStatus, models.ErrNoEmailFound, nil},
+			},
+		},
+		{
+			name: "success/Results on a site already tried are passed over",
+			args: args{candidateURLs: []string{"https://bad.test/", "https://bad.test/about", "https://good.test/"}, maxSites: 2},
+			expected: expected{
+				email:       "info@good.test",
+				url:         "https://good.test/",
+				attempts:    []string{"https://bad.test/", "https://good.test/"},
+				attemptErrs: []error{models.ErrNonOKStatus, nil},
+			},
+		},
+		{
+			name: "success/Facebook page without a website gives way to the next result",
+			args: args{candidateURLs: []string{"https://www.facebook.com/fans", "https://good.test/"}},
+			expected: expected{
+				email:       "info@good.test",
+				url:         "https://good.test/",
+				attempts:    []string{"https://www.facebook.com/fans", "https://good.test/"},
+				attemptErrs: []error{models.ErrSkippingFacebookURL, nil},
+			},
+		},
+		{
+			name: "success/Phone numbers are kept when no site yields an email",
+			args: args{candidateURLs: []string{"https://phones.test/", "https://noemail.test/"}},
+			expected: expected{
+				url:         "https://phones.test/",
+				phones:      []string{"+49301234567"},
+				attempts:    []string{"https://phones.test/", "https://noemail.test/"},
+				attemptErrs: []error{nil, models.ErrNoEmailFound},
+			},
+		},
+		{
+			name: "error/Stops after the maximum number of sites",
+			args: args{candidateURLs: []string{"https://bad.test/", "https://noemail.test/", "https://good.test/"}, maxSites: 2},
+			expected: expected{
+				attempts:    []string{"https://bad.test/", "https://noemail.test/"},
+				attemptErrs: []error{models.ErrNonOKStatus, models.ErrNoEmailFound},
+				err:         models.ErrNoUsableSite,
+			},
+		},
+		{
+			name:     "error/No results",
+			expected: expected{err: models.ErrNoResultsFound},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Set("scraper.max_sites", tc.args.maxSites)
+			defer viper.Set("scraper.max_sites", 0)
+
+			company, err := ResolveCompanyContacts(context.Background(), client, EmailChecks{}, tc.args.candidateURLs, "Acme", "")
+
+			assert.ErrorIs(t, err, tc.expected.err)
+			assert.Equal(t, "Acme", company.Name)
+			assert.Equal(t, tc.expected.email, company.Email)
+			assert.Equal(t, tc.expected.url, company.URL)
+			assert.Equal(t, tc.expected.phones, company.Phones)
+
+			var attempts []string
+
+			for i, attempt := range company.Attempts {
+				attempts = append(attempts, attempt.URL)
+
+				if i < len(tc.expected.attemptErrs) {
+					assert.ErrorIs(t, attempt.Err, tc.expected.attemptErrs[i])
+				}
+			}
+
+			assert.Equal(t, tc.expected.attempts, attempts)
+		})
+	}
+}
+
+func TestLookupCompany(t *testing.T) {
+	viper.Set("serpapi.api_key", "valid_api_key")
+
+	searchClient := &MockClient{
+		MockDo: func(_ *http.Request) (*http.Response, error) {
+			return mockHTTPResponse(http.StatusOK, `{"organic": [{"link": "https://noemail.test/"}, {"link": "https://good.test/"}]}`), nil
+		},
+	}
+
+	fetchClient := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			switch {
+			case req.URL.Path != "/":
+				return mockHTTPResponse(http.StatusNotFound, ""), nil
+			case req.URL.Host == "good.test":
+				return mockHTTPResponse(http.StatusOK, `<p>Write to info@good.test</p>`), nil
+			default:
+				return mockHTTPResponse(http.StatusOK, `<p>Welcome</p>`), nil
+			}
+		},
+	}
+
+	company, err := LookupCompany(context.Background(), searchClient, fetchClient, EmailChecks{}, "Acme", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "info@good.test", company.Email)
+	assert.Equal(t, "https://good.test/", company.URL)
+	assert.Len(t, company.Attempts, 2)
+
+	failing := &MockClient{
+		MockDo: func(_ *http.Request) (*http.Response, error) {
+			return mockHTTPResponse(http.StatusOK, `{"organic": []}`), nil
+		},
+	}
+
+	company, err = LookupCompany(context.Background(), failing, fetchClient, EmailChecks{}, "Acme", "")
+
+	assert.ErrorIs(t, err, models.ErrNoResultsFound)
+	assert.Equal(t, "Acme", company.Name)
+}
@@ -0,0 +1,283 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+const (
+	// RFC 9309 asks crawlers to parse at least the first 500 KiB of robots.txt
+	maxRobotsBytes = 500 << 10
+	// Longest Crawl-delay honored, so one site cannot stall a whole run
+	maxCrawlDelay = 30 * time.Second
+)
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsPolicy is the parsed robots.txt of one origin
+type robotsPolicy struct {
+	groups      []robotsGroup
+	sitemaps    []string
+	disallowAll bool      // robots.txt was unreachable (5xx), see RFC 9309 section 2.3.1.4
+	fetched     time.Time // when robots.txt was requested, for the cache lifetime
+}
+
+// parseRobots reads the user-agent groups, rules, Crawl-delay and Sitemap entries of a robots.txt
+func parseRobots(r io.Reader) *robotsPolicy {
+	policy := &robotsPolicy{}
+
+	var group *robotsGroup
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsBytes))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share one group
+			if group == nil || len(group.rules) > 0 || group.crawlDelay > 0 {
+				policy.groups = append(policy.groups, robotsGroup{})
+				group = &policy.groups[len(policy.groups)-1]
+			}
+
+			group.agents = append(group.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{pattern: value, allow: key == "allow"})
+			}
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); group != nil && err == nil && seconds > 0 {
+				group.crawlDelay = min(time.Duration(seconds*float64(time.Second)), maxCrawlDelay)
+			}
+		case "sitemap":
+			policy.sitemaps = append(policy.sitemaps, value)
+		}
+	}
+
+	return policy
+}
+
+// matchingGroups returns the groups naming the user-agent token, or the "*" groups if none do
+func (p *robotsPolicy) matchingGroups(userAgent string) []robotsGroup {
+	var named, wildcard []robotsGroup
+
+	for _, group := range p.groups {
+		for _, agent := range group.agents {
+			switch {
+			case agent == "*":
+				wildcard = append(wildcard, group)
+			case strings.EqualFold(agent, userAgent):
+				named = append(named, group)
+			default:
+				continue
+			}
+
+			break
+		}
+	}
+
+	if len(named) > 0 {
+		return named
+	}
+
+	return wildcard
+}
+
+// allowed applies the most specific matching rule to the path; Allow wins ties
+func (p *robotsPolicy) allowed(userAgent, path string) bool {
+	if p.disallowAll {
+		return false
+	}
+
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allow, longest := true, -1
+
+	for _, group := range p.matchingGroups(userAgent) {
+		for _, rule := range group.rules {
+			if !robotsPatternMatch(rule.pattern, path) {
+				continue
+			}
+
+			if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+				allow, longest = rule.allow, len(rule.pattern)
+			}
+		}
+	}
+
+	return allow
+}
+
+func (p *robotsPolicy) crawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+
+	for _, group := range p.matchingGroups(userAgent) {
+		delay = max(delay, group.crawlDelay)
+	}
+
+	return delay
+}
+
+// robotsPatternMatch matches a rule path against a URL path, supporting the
+// "*" wildcard and the "$" end anchor
+func robotsPatternMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+
+	pos := len(parts[0])
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+
+		index := strings.Index(path[pos:], part)
+		if index < 0 {
+			return false
+		}
+
+		pos += index + len(part)
+	}
+
+	return !anchored || pos == len(path)
+}
+
+// robotsFor returns the cached robots.txt policy of the URL's origin, fetching it on first
+// use and again once the cached one is a day old.
+// A missing robots.txt (4xx) allows everything; a server error (5xx) disallows everything.
+// When robots.txt cannot be requested at all, the site is down rather than disallowing
+// anything: the error is returned, wrapping models.ErrFetchFailed, and nothing is cached.
+func (c *Crawler) robotsFor(ctx context.Context, target *url.URL) (*robotsPolicy, error) {
+	origin := target.Scheme + "://" + strings.ToLower(target.Host)
+
+	c.sites.mu.Lock()
+	policy, cached := c.sites.robots[origin]
+	c.sites.mu.Unlock()
+
+	if cached && time.Since(policy.fetched) < robotsCacheTTL {
+		return policy, nil
+	}
+
+	policy = &robotsPolicy{}
+	fetched := time.Now()
+
+	err := c.do(ctx, origin+"/robots.txt", func(resp *http.Response) error {
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			policy = parseRobots(resp.Body)
+		case resp.StatusCode >= http.StatusInternalServerError:
+			policy.disallowAll = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policy.fetched = fetched
+
+	c.sites.mu.Lock()
+	c.sites.robots[origin] = policy
+	c.sites.mu.Unlock()
+
+	return policy, nil
+}
+
+// checkRobots returns models.ErrDisallowedByRobots when robots.txt forbids fetching
+// rawURL, and otherwise waits out the origin's Crawl-delay since the previous request,
+// or until ctx is done. Rules are matched against the product token of the User-Agent
+// sent to the host.
+func (c *Crawler) checkRobots(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", models.ErrFetchFailed, err)
+	}
+
+	policy, err := c.robotsFor(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+
+	token := robotsToken(c.userAgentFor(target.Host))
+
+	if !policy.allowed(token, path) {
+		return fmt.Errorf("%w: %s", models.ErrDisallowedByRobots, rawURL)
+	}
+
+	// the request's turn is reserved under the lock and waited for without it, so
+	// requests to the same origin queue up while other origins go ahead
+	origin := target.Scheme + "://" + strings.ToLower(target.Host)
+
+	c.sites.mu.Lock()
+
+	turn := c.sites.lastVisit[origin].Add(policy.crawlDelay(token))
+	if now := time.Now(); turn.Before(now) {
+		turn = now
+	}
+
+	c.sites.lastVisit[origin] = turn
+	c.sites.mu.Unlock()
+
+	return waitUntil(ctx, turn)
+}
+
+// waitUntil blocks until t, failing with models.ErrFetchFailed if ctx is done first
+func waitUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", models.ErrFetchFailed, ctx.Err())
+	}
+}
@@ -0,0 +1,334 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestRobotsPolicyAllowed(t *testing.T) {
+	robotsTxt := `# example robots.txt
+User-agent: *
+Disallow: /private/
+Disallow: /*.php$
+Allow: /private/contact
+Crawl-delay: 2
+
+User-agent: BadBot
+User-agent: CompanyEmailScraper
+Disallow: /team
+Allow: /team/contact$
+
+Sitemap: https://acme.test/sitemap.xml
+`
+
+	type args struct {
+		userAgent string
+		path      string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected bool
+	}{
+		{name: "Unlisted path is allowed", args: args{userAgent: "OtherBot", path: "/about"}, expected: true},
+		{name: "Disallowed prefix", args: args{userAgent: "OtherBot", path: "/private/data"}, expected: false},
+		{name: "Longer allow rule wins", args: args{userAgent: "OtherBot", path: "/private/contact"}, expected: true},
+		{name: "Wildcard with end anchor", args: args{userAgent: "OtherBot", path: "/index.php"}, expected: false},
+		{name: "End anchor does not match longer path", args: args{userAgent: "OtherBot", path: "/index.php?x=1"}, expected: true},
+		{name: "Named group replaces the wildcard group", args: args{userAgent: "companyemailscraper", path: "/private/data"}, expected: true},
+		{name: "Named group rule", args: args{userAgent: "CompanyEmailScraper", path: "/team/jane"}, expected: false},
+		{name: "Named group anchored allow", args: args{userAgent: "CompanyEmailScraper", path: "/team/contact"}, expected: true},
+		{name: "Robots.txt itself is always allowed", args: args{userAgent: "BadBot", path: "/robots.txt"}, expected: true},
+	}
+
+	policy := parseRobots(strings.NewReader(robotsTxt))
+
+	assert.Equal(t, []string{"https://acme.test/sitemap.xml"}, policy.sitemaps)
+	assert.Equal(t, 2*time.Second, policy.crawlDelay("OtherBot"))
+	assert.Equal(t, time.Duration(0), policy.crawlDelay("CompanyEmailScraper"))
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policy.allowed(tc.args.userAgent, tc.args.path))
+		})
+	}
+}
+
+func TestCrawlerCrawlRobots(t *testing.T) {
+	type dependencies struct {
+		robotsStatus int
+		robotsTxt    string
+	}
+
+	type expected struct {
+		statuses []models.PageStatus
+		err      error
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		expected     expected
+	}{
+		{
+			name: "success/Disallowed contact page is skipped with its own status",
+			dependencies: dependencies{
+				robotsStatus: http.StatusOK,
+				robotsTxt:    "User-agent: *\nDisallow: /contact\n",
+			},
+			expected: expected{
+				statuses: []models.PageStatus{models.PageFetched, models.PageDisallowed},
+			},
+		},
+		{
+			name: "success/Missing robots.txt allows everything",
+			dependencies: dependencies{
+				robotsStatus: http.StatusNotFound,
+			},
+			expected: expected{
+				statuses: []models.PageStatus{models.PageFetched, models.PageFetched},
+			},
+		},
+		{
+			name: "error/Start page disallowed",
+			dependencies: dependencies{
+				robotsStatus: http.StatusOK,
+				robotsTxt:    "User-agent: CompanyEmailScraper\nDisallow: /\n",
+			},
+			expected: expected{
+				statuses: []models.PageStatus{models.PageDisallowed},
+				err:      models.ErrDisallowedByRobots,
+			},
+		},
+		{
+			name: "error/Server error on robots.txt disallows everything",
+			dependencies: dependencies{
+				robotsStatus: http.StatusServiceUnavailable,
+			},
+			expected: expected{
+				statuses: []models.PageStatus{models.PageDisallowed},
+				err:      models.ErrDisallowedByRobots,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var robotsRequests int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body string
+
+				switch r.URL.Path {
+				case "/robots.txt":
+					robotsRequests++
+
+					assert.Equal(t, defaultUserAgent, r.Header.Get("User-Agent"))
+					w.WriteHeader(tc.dependencies.robotsStatus)
+
+					body = tc.dependencies.robotsTxt
+				case "/":
+					body = `<a href="/contact">Contact</a>`
+				case "/contact":
+					body = `<p>info@acme.test</p>`
+				default:
+					http.NotFound(w, r)
+
+					return
+				}
+
+				_, err := w.Write([]byte(body))
+				if err != nil {
+					t.Error("failed to write mock response")
+				}
+			}))
+			defer server.Close()
+
+			opts := DefaultCrawlOptions()
+			opts.Sitemaps = false
+
+			result, err := NewCrawler(server.Client(), opts).Crawl(context.Background(), server.URL+"/")
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var statuses []models.PageStatus
+			for _, page := range result.Pages {
+				statuses = append(statuses, page.Status)
+			}
+
+			assert.Equal(t, tc.expected.statuses, statuses)
+			assert.Equal(t, 1, robotsRequests, "robots.txt should be fetched once per origin")
+		})
+	}
+}
+
+func TestCrawlerRobotsNetworkError(t *testing.T) {
+	var robotsRequests, pageRequests int
+
+	client := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/robots.txt" {
+				robotsRequests++
+
+				return nil, models.ErrNetwork
+			}
+
+			pageRequests++
+
+			return mockHTTPResponse(http.StatusOK, `<p>info@acme.test</p>`), nil
+		},
+	}
+
+	crawler := NewCrawler(client, DefaultCrawlOptions())
+
+	for _, pageURL := range []string{"https://acme.test/", "https://acme.test/contact"} {
+		err := crawler.checkRobots(context.Background(), pageURL)
+
+		assert.ErrorIs(t, err, models.ErrFetchFailed)
+		assert.ErrorIs(t, err, models.ErrNetwork)
+		assert.NotErrorIs(t, err, models.ErrDisallowedByRobots, "a site that is down disallows nothing")
+	}
+
+	result, err := crawler.Crawl(context.Background(), "https://acme.test/")
+
+	assert.ErrorIs(t, err, models.ErrFetchFailed)
+	assert.Equal(t, []models.PageResult{{URL: "https://acme.test/", Status: models.PageFailed, Err: err}}, result.Pages)
+	assert.Equal(t, 3, robotsRequests, "a failed robots.txt request is not cached")
+	assert.Zero(t, pageRequests)
+}
+
+func TestCrawlerRobotsUserAgentPool(t *testing.T) {
+	var userAgents []string
+
+	client := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			userAgents = append(userAgents, req.Header.Get("User-Agent"))
+
+			if req.URL.Path == "/robots.txt" {
+				return mockHTTPResponse(http.StatusOK, "User-agent: PoolAgent\nDisallow: /contact\n"), nil
+			}
+
+			return mockHTTPResponse(http.StatusOK, `<p>info@acme.test</p>`), nil
+		},
+	}
+
+	opts := DefaultCrawlOptions()
+	opts.UserAgents = []string{"PoolAgent/1.0"}
+
+	crawler := NewCrawler(client, opts)
+
+	assert.NoError(t, crawler.checkRobots(context.Background(), "https://acme.test/"))
+	assert.ErrorIs(t, crawler.checkRobots(context.Background(), "https://acme.test/contact"), models.ErrDisallowedByRobots)
+	assert.Equal(t, []string{"PoolAgent/1.0"}, userAgents)
+}
+
+func TestCrawlerCrawlDelay(t *testing.T) {
+	server := newTestSite(t, map[string]string{
+		"/robots.txt": "User-agent: *\nCrawl-delay: 0.2\n",
+		"/":           `<a href="/contact">Contact</a><a href="/about">About</a>`,
+		"/contact":    `<p>info@acme.test</p>`,
+		"/about":      `<p>about</p>`,
+	})
+
+	opts := DefaultCrawlOptions()
+	opts.Sitemaps = false
+
+	start := time.Now()
+
+	result, err := NewCrawler(server.Client(), opts).Crawl(context.Background(), server.URL+"/")
+	assert.NoError(t, err)
+	assert.Len(t, result.Pages, 3)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestCrawlerCrawlDelayWait(t *testing.T) {
+	client := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "slow.test" && req.URL.Path == "/robots.txt" {
+				return mockHTTPResponse(http.StatusOK, "User-agent: *\nCrawl-delay: 30\n"), nil
+			}
+
+			return mockHTTPResponse(http.StatusNotFound, ""), nil
+		},
+	}
+
+	crawler := NewCrawler(client, DefaultCrawlOptions())
+
+	assert.NoError(t, crawler.checkRobots(context.Background(), "https://slow.test/"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waited := make(chan error)
+
+	go func() {
+		waited <- crawler.checkRobots(ctx, "https://slow.test/contact")
+	}()
+
+	// another origin goes ahead while the first waits out its Crawl-delay
+	done := make(chan error)
+
+	go func() {
+		done <- crawler.checkRobots(context.Background(), "https://fast.test/")
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("another origin was blocked by the Crawl-delay")
+	}
+
+	cancel()
+
+	select {
+	case err := <-waited:
+		assert.ErrorIs(t, err, models.ErrFetchFailed)
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the Crawl-delay wait was not cancelled")
+	}
+}
+
+func TestCrawlerSharedSites(t *testing.T) {
+	var robotsRequests int
+
+	client := &MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/robots.txt" {
+				robotsRequests++
+
+				return mockHTTPResponse(http.StatusOK, "User-agent: *\nCrawl-delay: 30\n"), nil
+			}
+
+			return mockHTTPResponse(http.StatusNotFound, ""), nil
+		},
+	}
+
+	fetchClient := NewFetchClient(client)
+
+	assert.NoError(t, NewCrawler(fetchClient, DefaultCrawlOptions()).checkRobots(context.Background(), "https://acme.test/"))
+
+	// a crawl for another company waits out the Crawl-delay of the first
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := NewCrawler(fetchClient, DefaultCrawlOptions()).checkRobots(ctx, "https://acme.test/contact")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, robotsRequests, "robots.txt is fetched once per fetch client")
+
+	// crawlers on a plain client remember nothing of each other
+	assert.NoError(t, NewCrawler(client, DefaultCrawlOptions()).checkRobots(context.Background(), "https://acme.test/contact"))
+	assert.Equal(t, 2, robotsRequests)
+}
@@ -0,0 +1,232 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// SelectionPolicy decides which of the ranked emails is reported for a company.
+type SelectionPolicy string
+
+const (
+	// PolicyBest picks the highest scoring address.
+	PolicyBest SelectionPolicy = "best"
+	// PolicySameDomain picks the highest scoring address on the company's own domain.
+	PolicySameDomain SelectionPolicy = "same-domain"
+	// PolicyFirst picks the first address found on the page.
+	PolicyFirst SelectionPolicy = "first"
+)
+
+// Score weights
+const (
+	sameDomainScore    = 50
+	relatedDomainScore = 40
+	aliasDomainScore   = 30
+	thirdPartyScore    = -60
+	preferredRoleScore = 20
+	avoidedRoleScore   = -30
+	repeatScore        = 2
+	maxRepeatBonus     = 10
+)
+
+// Local parts a sales team can actually write to
+var preferredRoles = map[string]bool{
+	"info": true, "contact": true, "sales": true, "hello": true, "office": true,
+	"enquiries": true, "inquiries": true, "mail": true, "team": true, "business": true,
+}
+
+// Local parts that are unattended or meant for other purposes
+var avoidedRoles = map[string]bool{
+	"noreply": true, "no-reply": true, "donotreply": true, "do-not-reply": true,
+	"privacy": true, "abuse": true, "postmaster": true, "hostmaster": true, "webmaster": true,
+	"dmca": true, "gdpr": true, "unsubscribe": true, "mailer-daemon": true,
+}
+
+// How much a single occurrence is trusted, by where it was found
+var sourceScores = map[models.EmailSource]float64{
+	models.SourceSchema:     15,
+	models.SourceMailto:     10,
+	models.SourceCloudflare: 10,
+	models.SourceAttribute:  8,
+	models.SourceObfuscated: 6,
+	models.SourceText:       5,
+}
+
+// ParseSelectionPolicy validates a policy name, defaulting to PolicyBest when empty.
+func ParseSelectionPolicy(name string) (SelectionPolicy, error) {
+	switch policy := SelectionPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return PolicyBest, nil
+	case PolicyBest, PolicySameDomain, PolicyFirst:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %s", models.ErrUnknownPolicy, name)
+	}
+}
+
+func getSelectionPolicy() (SelectionPolicy, error) {
+	return ParseSelectionPolicy(viper.GetString("scraper.selection_policy"))
+}
+
+// RankEmails merges repeated candidates into distinct addresses and orders them by
+// score: domain match with the company site, role preference, frequency and source.
+func RankEmails(candidates []models.EmailCandidate, siteURL string) []models.RankedEmail {
+	siteHost := hostOf(siteURL)
+	lists := getDomainLists()
+	index := make(map[string]int)
+
+	var ranked []models.RankedEmail
+
+	for position, candidate := range candidates {
+		ascii := candidate.ASCII
+		if ascii == "" {
+			ascii = candidate.Address
+		}
+
+		// the ASCII form merges an address seen with both a Unicode and a punycode domain
+		key := strings.ToLower(ascii)
+
+		i, seen := index[key]
+		if !seen {
+			i = len(ranked)
+			index[key] = i
+			ranked = append(ranked, models.RankedEmail{Address: candidate.Address, ASCII: ascii, Position: position})
+		}
+
+		ranked[i].Count++
+		if !containsSource(ranked[i].Sources, candidate.Source) {
+			ranked[i].Sources = append(ranked[i].Sources, candidate.Source)
+		}
+	}
+
+	for i := range ranked {
+		scoreEmail(&ranked[i], siteHost, lists)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	return ranked
+}
+
+func scoreEmail(email *models.RankedEmail, siteHost string, lists domainLists) {
+	local, domain, _ := strings.Cut(strings.ToLower(email.ASCII), "@")
+
+	email.Relation = domainRelation(domain, siteHost, lists)
+
+	switch email.Relation {
+	case models.DomainSame:
+		email.SameDomain = true
+		email.Score += sameDomainScore
+	case models.DomainRelated:
+		email.SameDomain = true
+		email.Score += relatedDomainScore
+	case models.DomainAlias:
+		email.SameDomain = true
+		email.Score += aliasDomainScore
+	case models.DomainThirdParty:
+		email.Score += thirdPartyScore
+	case models.DomainWebmail, models.DomainOther:
+	}
+
+	switch {
+	case preferredRoles[local]:
+		email.Score += preferredRoleScore
+	case avoidedRoles[local]:
+		email.Score += avoidedRoleScore
+	}
+
+	email.Score += min(float64(email.Count-1)*repeatScore, maxRepeatBonus)
+
+	var best float64
+	for _, source := range email.Sources {
+		best = max(best, sourceScores[source])
+	}
+
+	email.Score += best
+}
+
+// SelectEmail applies the policy to a ranked list built by RankEmails. Emails of third-party
+// services are never selected. With excludeUndeliverable, neither are emails found to be
+// undeliverable nor those whose mailbox was rejected; otherwise the checks only report them.
+func SelectEmail(ranked []models.RankedEmail, policy SelectionPolicy, excludeUndeliverable bool) (models.RankedEmail, error) {
+	usable := make([]models.RankedEmail, 0, len(ranked))
+	for _, email := range ranked {
+		if excludedReason(email, excludeUndeliverable) == nil {
+			usable = append(usable, email)
+		}
+	}
+
+	if len(usable) == 0 && len(ranked) > 0 {
+		return models.RankedEmail{}, fmt.Errorf("%w: %s", excludedReason(ranked[0], excludeUndeliverable), ranked[0].Address)
+	}
+
+	ranked = usable
+
+	switch policy {
+	case PolicyBest:
+		if len(ranked) > 0 {
+			return ranked[0], nil
+		}
+	case PolicySameDomain:
+		for _, email := range ranked {
+			if email.SameDomain {
+				return email, nil
+			}
+		}
+	case PolicyFirst:
+		if len(ranked) > 0 {
+			first := ranked[0]
+			for _, email := range ranked[1:] {
+				if email.Position < first.Position {
+					first = email
+				}
+			}
+
+			return first, nil
+		}
+	default:
+		return models.RankedEmail{}, fmt.Errorf("%w: %s", models.ErrUnknownPolicy, policy)
+	}
+
+	return models.RankedEmail{}, models.ErrNoEmailFound
+}
+
+// excludedReason returns why an email can never be selected, or nil when it can be
+func excludedReason(email models.RankedEmail, excludeUndeliverable bool) error {
+	switch {
+	case email.Relation == models.DomainThirdParty:
+		return models.ErrThirdPartyEmail
+	case excludeUndeliverable && (email.Deliverability == models.Undeliverable || email.Mailbox == models.MailboxRejected):
+		return models.ErrUndeliverable
+	}
+
+	return nil
+}
+
+func containsSource(sources []models.EmailSource, source models.EmailSource) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostOf returns the lower-cased host of a URL without a leading "www."
+func hostOf(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(asciiHost(parsedURL.Hostname()), "www.")
+}
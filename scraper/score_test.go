@@ -0,0 +1,287 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestRankEmails(t *testing.T) {
+	type args struct {
+		candidates []models.EmailCandidate
+		siteURL    string
+	}
+
+	type expected struct {
+		addresses []string
+		first     models.RankedEmail
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Company domain beats third-party theme credit",
+			args: args{
+				candidates: []models.EmailCandidate{
+					{Address: "dev@themeforest.net", Source: models.SourceText},
+					{Address: "info@acme.com", Source: models.SourceText},
+				},
+				siteURL: "https://www.acme.com/",
+			},
+			expected: expected{
+				addresses: []string{"info@acme.com", "dev@themeforest.net"},
+				first: models.RankedEmail{
+					Address:    "info@acme.com",
+					ASCII:      "info@acme.com",
+					Score:      sameDomainScore + preferredRoleScore + 5,
+					Count:      1,
+					Sources:    []models.EmailSource{models.SourceText},
+					SameDomain: true,
+					Position:   1,
+					Relation:   models.DomainSame,
+				},
+			},
+		},
+		{
+			name: "success/Preferred role beats unattended role on the same domain",
+			args: args{
+				candidates: []models.EmailCandidate{
+					{Address: "noreply@acme.com", Source: models.SourceMailto},
+					{Address: "webmaster@acme.com", Source: models.SourceText},
+					{Address: "sales@acme.com", Source: models.SourceText},
+				},
+				siteURL: "https://acme.com",
+			},
+			expected: expected{
+				addresses: []string{"sales@acme.com", "noreply@acme.com", "webmaster@acme.com"},
+				first: models.RankedEmail{
+					Address:    "sales@acme.com",
+					ASCII:      "sales@acme.com",
+					Score:      sameDomainScore + preferredRoleScore + 5,
+					Count:      1,
+					Sources:    []models.EmailSource{models.SourceText},
+					SameDomain: true,
+					Position:   2,
+					Relation:   models.DomainSame,
+				},
+			},
+		},
+		{
+			name: "success/Repeated address merged case-insensitively with all sources",
+			args: args{
+				candidates: []models.EmailCandidate{
+					{Address: "jane@acme.com", Source: models.SourceText},
+					{Address: "Hello@acme.com", Source: models.SourceText},
+					{Address: "hello@acme.com", Source: models.SourceMailto},
+					{Address: "HELLO@acme.com", Source: models.SourceText},
+				},
+				siteURL: "https://shop.acme.com",
+			},
+			expected: expected{
+				addresses: []string{"Hello@acme.com", "jane@acme.com"},
+				first: models.RankedEmail{
+					Address:    "Hello@acme.com",
+					ASCII:      "Hello@acme.com",
+					Score:      relatedDomainScore + preferredRoleScore + 2*repeatScore + 10,
+					Count:      3,
+					Sources:    []models.EmailSource{models.SourceText, models.SourceMailto},
+					SameDomain: true,
+					Position:   1,
+					Relation:   models.DomainRelated,
+				},
+			},
+		},
+		{
+			name: "success/Unicode and punycode forms of one address are merged",
+			args: args{
+				candidates: []models.EmailCandidate{
+					{Address: "info@bücher.example", ASCII: "info@xn--bcher-kva.example", Source: models.SourceText},
+					{Address: "info@xn--bcher-kva.example", ASCII: "info@xn--bcher-kva.example", Source: models.SourceMailto},
+				},
+				siteURL: "https://www.Bücher.example/",
+			},
+			expected: expected{
+				addresses: []string{"info@bücher.example"},
+				first: models.RankedEmail{
+					Address:    "info@bücher.example",
+					ASCII:      "info@xn--bcher-kva.example",
+					Score:      sameDomainScore + preferredRoleScore + repeatScore + 10,
+					Count:      2,
+					Sources:    []models.EmailSource{models.SourceText, models.SourceMailto},
+					SameDomain: true,
+					Position:   0,
+					Relation:   models.DomainSame,
+				},
+			},
+		},
+		{
+			name: "success/Ties keep document order",
+			args: args{
+				candidates: []models.EmailCandidate{
+					{Address: "jane@example.org", Source: models.SourceText},
+					{Address: "john@example.org", Source: models.SourceText},
+				},
+				siteURL: "",
+			},
+			expected: expected{
+				addresses: []string{"jane@example.org", "john@example.org"},
+				first: models.RankedEmail{
+					Address:  "jane@example.org",
+					ASCII:    "jane@example.org",
+					Score:    5,
+					Count:    1,
+					Sources:  []models.EmailSource{models.SourceText},
+					Position: 0,
+					Relation: models.DomainOther,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ranked := RankEmails(tc.args.candidates, tc.args.siteURL)
+
+			addresses := make([]string, 0, len(ranked))
+			for _, email := range ranked {
+				addresses = append(addresses, email.Address)
+			}
+
+			assert.Equal(t, tc.expected.addresses, addresses)
+			assert.Equal(t, tc.expected.first, ranked[0])
+		})
+	}
+}
+
+func TestSelectEmail(t *testing.T) {
+	ranked := []models.RankedEmail{
+		{Address: "studio@agency.io", Score: 30, Position: 0},
+		{Address: "jane@acme.com", Score: 25, SameDomain: true, Position: 2},
+		{Address: "info@acme.com", Score: 20, SameDomain: true, Position: 1},
+	}
+
+	checked := []models.RankedEmail{
+		{Address: "info@parked.com", Score: 30, Deliverability: models.Undeliverable},
+		{Address: "old@acme.com", Score: 25, Deliverability: models.DeliverableMX, Mailbox: models.MailboxRejected},
+		{Address: "info@acme.com", Score: 20, Deliverability: models.DeliverabilityUnknown},
+	}
+
+	thirdParty := []models.RankedEmail{
+		{Address: "support@wix.com", Score: 40, Relation: models.DomainThirdParty},
+		{Address: "hello@acme.com", Score: 30, SameDomain: true, Relation: models.DomainSame},
+	}
+
+	type args struct {
+		ranked               []models.RankedEmail
+		policy               SelectionPolicy
+		excludeUndeliverable bool
+	}
+
+	type expected struct {
+		address string
+		err     error
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name:     "success/Best policy picks the highest score",
+			args:     args{ranked: ranked, policy: PolicyBest},
+			expected: expected{address: "studio@agency.io"},
+		},
+		{
+			name:     "success/Same-domain policy skips third-party addresses",
+			args:     args{ranked: ranked, policy: PolicySameDomain},
+			expected: expected{address: "jane@acme.com"},
+		},
+		{
+			name:     "success/First policy picks the first address on the page",
+			args:     args{ranked: ranked[1:], policy: PolicyFirst},
+			expected: expected{address: "info@acme.com"},
+		},
+		{
+			name:     "success/Undeliverable addresses and rejected mailboxes are skipped when excluded",
+			args:     args{ranked: checked, policy: PolicyBest, excludeUndeliverable: true},
+			expected: expected{address: "info@acme.com"},
+		},
+		{
+			name:     "success/Undeliverable addresses are only reported unless excluded",
+			args:     args{ranked: checked, policy: PolicyBest},
+			expected: expected{address: "info@parked.com"},
+		},
+		{
+			name:     "success/Third-party service addresses are skipped",
+			args:     args{ranked: thirdParty, policy: PolicyBest},
+			expected: expected{address: "hello@acme.com"},
+		},
+		{
+			name:     "error/Only third-party service addresses",
+			args:     args{ranked: thirdParty[:1], policy: PolicyBest},
+			expected: expected{err: models.ErrThirdPartyEmail},
+		},
+		{
+			name:     "error/Only undeliverable addresses",
+			args:     args{ranked: checked[:2], policy: PolicyBest, excludeUndeliverable: true},
+			expected: expected{err: models.ErrUndeliverable},
+		},
+		{
+			name:     "error/Same-domain policy without a company address",
+			args:     args{ranked: ranked[:1], policy: PolicySameDomain},
+			expected: expected{err: models.ErrNoEmailFound},
+		},
+		{
+			name:     "error/Empty list",
+			args:     args{ranked: nil, policy: PolicyBest},
+			expected: expected{err: models.ErrNoEmailFound},
+		},
+		{
+			name:     "error/Unknown policy",
+			args:     args{ranked: ranked, policy: "random"},
+			expected: expected{err: models.ErrUnknownPolicy},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			email, err := SelectEmail(tc.args.ranked, tc.args.policy, tc.args.excludeUndeliverable)
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected.address, email.Address)
+		})
+	}
+}
+
+func TestParseSelectionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected SelectionPolicy
+		err      error
+	}{
+		"Empty defaults to best": {name: "", expected: PolicyBest},
+		"Case insensitive":       {name: " Same-Domain ", expected: PolicySameDomain},
+		"First":                  {name: "first", expected: PolicyFirst},
+		"Unknown":                {name: "longest", err: models.ErrUnknownPolicy},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := ParseSelectionPolicy(tc.name)
+
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
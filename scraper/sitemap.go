@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+const (
+	// Sitemap documents (indexes included) fetched per site
+	maxSitemapDocuments = 5
+	// Upper bound on a sitemap's size once decompressed when the crawler sets no body
+	// limit; the protocol allows 50MB
+	maxSitemapBytes = 50 << 20
+)
+
+// sitemapDocument covers both a <urlset> and a <sitemapindex>
+type sitemapDocument struct {
+	URLs     []sitemapLocation `xml:"url"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+type sitemapLocation struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapPages returns the contact-like pages of the site listed in the sitemaps
+// declared in robots.txt and at /sitemap.xml, most promising first, fetching at most
+// budget sitemap documents. Every sitemap fetched is recorded like a crawled page.
+func (c *Crawler) sitemapPages(ctx context.Context, siteURL, siteHost string, budget int) ([]string, []models.PageResult) {
+	parsedURL, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	root := &url.URL{Scheme: parsedURL.Scheme, Host: parsedURL.Host}
+
+	var queue []string
+
+	// an unreachable robots.txt is recorded when /sitemap.xml fails the same way
+	if policy, err := c.robotsFor(ctx, root); err == nil {
+		queue = append(queue, policy.sitemaps...)
+	}
+
+	queue = append(queue, root.JoinPath("sitemap.xml").String())
+	seen := make(map[string]bool)
+
+	var (
+		found   []scoredLink
+		fetched []models.PageResult
+	)
+
+	for len(queue) > 0 && len(fetched) < min(budget, maxSitemapDocuments) {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+
+		if seen[sitemapURL] {
+			continue
+		}
+
+		seen[sitemapURL] = true
+
+		document, err := c.fetchSitemap(ctx, sitemapURL)
+
+		fetched = append(fetched, models.PageResult{URL: sitemapURL, Depth: 1, Status: pageStatus(err), Err: err})
+		if err != nil {
+			continue
+		}
+
+		for _, sitemap := range document.Sitemaps {
+			queue = append(queue, strings.TrimSpace(sitemap.Loc))
+		}
+
+		for _, page := range document.URLs {
+			pageURL, err := url.Parse(strings.TrimSpace(page.Loc))
+			if err != nil || hostOf(pageURL.String()) != siteHost {
+				continue
+			}
+
+			if score := contactPageScore(pageURL.Path, ""); score > 0 {
+				found = append(found, scoredLink{url: pageURL.String(), score: score})
+			}
+		}
+	}
+
+	return byScore(found), fetched
+}
+
+// fetchSitemap downloads and decodes a sitemap, transparently gunzipping
+// compressed ones such as sitemap.xml.gz. The crawler's body limit applies to the
+// sitemap both as sent and once decompressed.
+func (c *Crawler) fetchSitemap(ctx context.Context, sitemapURL string) (sitemapDocument, error) {
+	var document sitemapDocument
+
+	limit := c.opts.MaxBodyBytes
+	if limit <= 0 {
+		limit = maxSitemapBytes
+	}
+
+	err := c.get(ctx, sitemapURL, func(resp *http.Response) error {
+		body := bufio.NewReader(&limitedReader{reader: resp.Body, remaining: limit})
+
+		var reader io.Reader = body
+
+		if magic, err := body.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+			gzipReader, err := gzip.NewReader(body)
+			if err != nil {
+				return fmt.Errorf("%w: %w", models.ErrInvalidSitemap, err)
+			}
+			defer gzipReader.Close()
+
+			reader = &limitedReader{reader: gzipReader, remaining: limit}
+		}
+
+		if err := xml.NewDecoder(reader).Decode(&document); errors.Is(err, models.ErrResponseTooLarge) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("%w: %w", models.ErrInvalidSitemap, err)
+		}
+
+		return nil
+	})
+
+	return document, err
+}
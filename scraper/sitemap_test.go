@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err := writer.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	return buf.String()
+}
+
+func TestCrawlerSitemapPages(t *testing.T) {
+	type dependencies struct {
+		files map[string]string // path to body; {{host}} is replaced with the server URL
+	}
+
+	type args struct {
+		budget       int
+		maxBodyBytes int64
+	}
+
+	type expected struct {
+		pages    []string
+		sitemaps map[string]models.PageStatus // by path
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		args         args
+		expected     expected
+	}{
+		{
+			name: "success/Default sitemap.xml",
+			dependencies: dependencies{
+				files: map[string]string{
+					"/sitemap.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>{{host}}/</loc></url>
+  <url><loc>{{host}}/blog/launch</loc></url>
+  <url><loc>{{host}}/about</loc></url>
+  <url><loc>{{host}}/contact-us/</loc></url>
+  <url><loc>https://elsewhere.example/contact</loc></url>
+</urlset>`,
+				},
+			},
+			args: args{budget: maxSitemapDocuments},
+			expected: expected{
+				pages:    []string{"/contact-us/", "/about"},
+				sitemaps: map[string]models.PageStatus{"/sitemap.xml": models.PageFetched},
+			},
+		},
+		{
+			name: "success/Robots.txt index pointing to a gzipped sitemap",
+			dependencies: dependencies{
+				files: map[string]string{
+					"/robots.txt": "User-agent: *\nDisallow: /admin\nSITEMAP: {{host}}/sitemap_index.xml\n",
+					"/sitemap_index.xml": `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>{{host}}/pages.xml.gz</loc></sitemap>
+  <sitemap><loc>{{host}}/missing.xml</loc></sitemap>
+</sitemapindex>`,
+					"/pages.xml.gz": "gzip:" + `<urlset><url><loc>{{host}}/de/impressum</loc></url><url><loc>{{host}}/shop</loc></url></urlset>`,
+				},
+			},
+			args: args{budget: maxSitemapDocuments},
+			expected: expected{
+				pages: []string{"/de/impressum"},
+				sitemaps: map[string]models.PageStatus{
+					"/sitemap_index.xml": models.PageFetched,
+					"/sitemap.xml":       models.PageFailed,
+					"/pages.xml.gz":      models.PageFetched,
+					"/missing.xml":       models.PageFailed,
+				},
+			},
+		},
+		{
+			name: "success/Sitemaps past the page budget are not fetched",
+			dependencies: dependencies{
+				files: map[string]string{
+					"/robots.txt":        "Sitemap: {{host}}/sitemap_index.xml\n",
+					"/sitemap_index.xml": `<sitemapindex><sitemap><loc>{{host}}/pages.xml</loc></sitemap></sitemapindex>`,
+					"/pages.xml":         `<urlset><url><loc>{{host}}/contact</loc></url></urlset>`,
+				},
+			},
+			args: args{budget: 1},
+			expected: expected{
+				pages:    []string{},
+				sitemaps: map[string]models.PageStatus{"/sitemap_index.xml": models.PageFetched},
+			},
+		},
+		{
+			name: "success/Malformed sitemap is ignored",
+			dependencies: dependencies{
+				files: map[string]string{
+					"/sitemap.xml": `<urlset><url><loc>{{host}}/contact`,
+				},
+			},
+			args: args{budget: maxSitemapDocuments},
+			expected: expected{
+				pages:    []string{},
+				sitemaps: map[string]models.PageStatus{"/sitemap.xml": models.PageFailed},
+			},
+		},
+		{
+			name: "success/Sitemap over the body limit is ignored",
+			dependencies: dependencies{
+				files: map[string]string{
+					"/sitemap.xml": "gzip:" + `<urlset><url><loc>{{host}}/contact</loc></url>` + strings.Repeat(" ", 4096) + `</urlset>`,
+				},
+			},
+			args: args{budget: maxSitemapDocuments, maxBodyBytes: 1024},
+			expected: expected{
+				pages:    []string{},
+				sitemaps: map[string]models.PageStatus{"/sitemap.xml": models.PageFailed},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var server *httptest.Server
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tc.dependencies.files[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+
+					return
+				}
+
+				body = strings.ReplaceAll(body, "{{host}}", server.URL)
+				if compressed, found := strings.CutPrefix(body, "gzip:"); found {
+					body = gzipString(t, compressed)
+				}
+
+				_, err := w.Write([]byte(body))
+				if err != nil {
+					t.Error("failed to write mock response")
+				}
+			}))
+			defer server.Close()
+
+			opts := DefaultCrawlOptions()
+			if tc.args.maxBodyBytes > 0 {
+				opts.MaxBodyBytes = tc.args.maxBodyBytes
+			}
+
+			pages, fetched := NewCrawler(server.Client(), opts).sitemapPages(context.Background(), server.URL+"/", hostOf(server.URL), tc.args.budget)
+
+			for i := range pages {
+				pages[i] = strings.TrimPrefix(pages[i], server.URL)
+			}
+
+			sitemaps := make(map[string]models.PageStatus)
+			for _, sitemap := range fetched {
+				sitemaps[strings.TrimPrefix(sitemap.URL, server.URL)] = sitemap.Status
+			}
+
+			assert.Equal(t, tc.expected.pages, pages)
+			assert.Equal(t, tc.expected.sitemaps, sitemaps)
+		})
+	}
+}
+
+func TestCrawlerCrawlUsesSitemap(t *testing.T) {
+	var server *httptest.Server
+
+	pages := map[string]string{
+		"/":               `<p>Welcome</p>`,
+		"/sitemap.xml":    `<urlset><url><loc>{{host}}/hidden-contact</loc></url></urlset>`,
+		"/hidden-contact": `<p>office@acme.test</p>`,
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, err := w.Write([]byte(strings.ReplaceAll(body, "{{host}}", server.URL)))
+		if err != nil {
+			t.Error("failed to write mock response")
+		}
+	}))
+	defer server.Close()
+
+	result, err := NewCrawler(server.Client(), DefaultCrawlOptions()).Crawl(context.Background(), server.URL+"/")
+	assert.NoError(t, err)
+	assert.Len(t, result.Pages, 3, "the start page, the sitemap and the page it lists")
+	assert.Len(t, result.Emails, 1)
+	assert.Equal(t, server.URL+"/hidden-contact", result.Emails[0].PageURL)
+
+	opts := DefaultCrawlOptions()
+	opts.Sitemaps = false
+
+	result, err = NewCrawler(server.Client(), opts).Crawl(context.Background(), server.URL+"/")
+	assert.NoError(t, err)
+	assert.Len(t, result.Pages, 1)
+	assert.Empty(t, result.Emails)
+}
@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RFC 9309 asks crawlers not to use a cached robots.txt for more than 24 hours
+const robotsCacheTTL = 24 * time.Hour
+
+// siteState is what crawlers remember about the sites they visit: the robots.txt policy
+// and the time of the next request allowed by Crawl-delay per origin, and the User-Agent
+// each host was given from a pool.
+type siteState struct {
+	mu         sync.Mutex
+	robots     map[string]*robotsPolicy // by origin
+	lastVisit  map[string]time.Time     // by origin, for Crawl-delay
+	userAgents map[string]string        // by host, when using a user-agent pool
+}
+
+func newSiteState() *siteState {
+	return &siteState{
+		robots:     make(map[string]*robotsPolicy),
+		lastVisit:  make(map[string]time.Time),
+		userAgents: make(map[string]string),
+	}
+}
+
+// FetchClient is the client for company sites. Crawlers using it share what they know
+// about each site for the client's lifetime, so robots.txt is fetched once per origin
+// and Crawl-delay holds across companies and concurrent lookups, not just within one
+// crawl.
+type FetchClient struct {
+	client HTTPClient
+	sites  *siteState
+}
+
+// NewFetchClient wraps the client that sends the requests to company sites.
+func NewFetchClient(client HTTPClient) *FetchClient {
+	return &FetchClient{client: client, sites: newSiteState()}
+}
+
+// ForLookup returns the client for one company's lookup. It shares the site state and the
+// connections of c, but an *http.Client with cookies gets a jar of its own, so that the
+// cookies a site sets during one lookup are not sent in another.
+func (c *FetchClient) ForLookup() *FetchClient {
+	httpClient, ok := c.client.(*http.Client)
+	if !ok || httpClient.Jar == nil {
+		return c
+	}
+
+	lookupClient := *httpClient
+	lookupClient.Jar = newCookieJar()
+
+	return &FetchClient{client: &lookupClient, sites: c.sites}
+}
+
+func (c *FetchClient) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req) //nolint:wrapcheck // callers wrap the errors of their client
+}
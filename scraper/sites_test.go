@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchClientForLookup(t *testing.T) {
+	// the site remembers a visitor by a cookie set on the first visit
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("visitor"); err == nil {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "visitor", Value: "1", Path: "/"})
+	}))
+	defer server.Close()
+
+	get := func(client HTTPClient) int {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	opts := DefaultHTTPClientOptions()
+	opts.Cookies = true
+
+	fetchClient := NewFetchClient(NewHTTPClient(opts))
+
+	first := fetchClient.ForLookup()
+	assert.Equal(t, http.StatusOK, get(first))
+	assert.Equal(t, http.StatusNoContent, get(first), "a lookup keeps its cookies")
+
+	second := fetchClient.ForLookup()
+	assert.Equal(t, http.StatusOK, get(second), "another lookup starts without them")
+	assert.Same(t, fetchClient.sites, second.sites, "the site state is shared")
+
+	plain := NewFetchClient(&MockClient{})
+	assert.Same(t, plain, plain.ForLookup(), "a client without a cookie jar is used as it is")
+}
@@ -0,0 +1,291 @@
+package scraper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// SMTPVerifyOptions configures the opt-in RCPT TO probe of found addresses.
+type SMTPVerifyOptions struct {
+	Enabled bool
+	// Server is the "host:port" every probe is sent to; empty probes each domain's MX hosts on Port
+	Server      string
+	Port        int
+	HeloName    string
+	MailFrom    string        // envelope sender; empty sends the null reverse-path "<>"
+	Timeout     time.Duration // whole conversation with one domain's server
+	Concurrency int           // domains probed at the same time
+}
+
+func DefaultSMTPVerifyOptions() SMTPVerifyOptions {
+	return SMTPVerifyOptions{
+		Port:        25,
+		HeloName:    "localhost",
+		Timeout:     10 * time.Second,
+		Concurrency: 4,
+	}
+}
+
+func getSMTPVerifyOptions() SMTPVerifyOptions {
+	opts := DefaultSMTPVerifyOptions()
+
+	if viper.IsSet("smtp_verify.enabled") {
+		opts.Enabled = viper.GetBool("smtp_verify.enabled")
+	}
+
+	strs := map[string]*string{
+		"smtp_verify.server":    &opts.Server,
+		"smtp_verify.helo_name": &opts.HeloName,
+		"smtp_verify.mail_from": &opts.MailFrom,
+	}
+	for key, value := range strs {
+		if viper.IsSet(key) {
+			*value = viper.GetString(key)
+		}
+	}
+
+	ints := map[string]*int{
+		"smtp_verify.port":        &opts.Port,
+		"smtp_verify.concurrency": &opts.Concurrency,
+	}
+	for key, value := range ints {
+		if viper.IsSet(key) {
+			*value = viper.GetInt(key)
+		}
+	}
+
+	if viper.IsSet("smtp_verify.timeout") {
+		opts.Timeout = viper.GetDuration("smtp_verify.timeout")
+	}
+
+	return opts
+}
+
+// SMTPVerifier asks mail servers whether they would accept mail for an address, stopping
+// after RCPT TO: it never sends DATA, so no message is ever delivered.
+type SMTPVerifier struct {
+	resolver Resolver
+	opts     SMTPVerifyOptions
+}
+
+func NewSMTPVerifier(resolver Resolver, opts SMTPVerifyOptions) *SMTPVerifier {
+	return &SMTPVerifier{
+		resolver: resolver,
+		opts:     opts,
+	}
+}
+
+// NewSMTPVerifierFromConfig returns a verifier built from the "smtp_verify" settings,
+// resolving MX hosts like the deliverability check, or nil unless the probe is enabled
+func NewSMTPVerifierFromConfig() *SMTPVerifier {
+	opts := getSMTPVerifyOptions()
+	if !opts.Enabled {
+		return nil
+	}
+
+	return NewSMTPVerifier(NewResolver(getDeliverabilityOptions().DNSServer), opts)
+}
+
+// VerifyEmails sets the Mailbox status of the ranked emails. Addresses are grouped by
+// domain and each domain is probed over one connection, at most Concurrency at a time.
+// Emails already known to be undeliverable are left alone.
+func (v *SMTPVerifier) VerifyEmails(ctx context.Context, ranked []models.RankedEmail) {
+	byDomain := make(map[string][]int)
+
+	for i, email := range ranked {
+		if email.Deliverability == models.Undeliverable {
+			continue
+		}
+
+		address := email.ASCII
+		if address == "" {
+			address = email.Address
+		}
+
+		domain := strings.ToLower(address[strings.LastIndex(address, "@")+1:])
+		byDomain[domain] = append(byDomain[domain], i)
+	}
+
+	var wg sync.WaitGroup
+
+	slots := make(chan struct{}, max(v.opts.Concurrency, 1))
+
+	for domain, indexes := range byDomain {
+		wg.Add(1)
+
+		go func(domain string, indexes []int) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			addresses := make([]string, len(indexes))
+			for i, index := range indexes {
+				addresses[i] = ranked[index].ASCII
+				if addresses[i] == "" {
+					addresses[i] = ranked[index].Address
+				}
+			}
+
+			// each goroutine writes only the indexes of its own domain
+			for i, status := range v.probeDomain(ctx, domain, addresses) {
+				ranked[indexes[i]].Mailbox = status
+			}
+		}(domain, indexes)
+	}
+
+	wg.Wait()
+}
+
+// probeDomain returns the status of each address, in order
+func (v *SMTPVerifier) probeDomain(ctx context.Context, domain string, addresses []string) []models.MailboxStatus {
+	statuses := make([]models.MailboxStatus, len(addresses))
+	for i := range statuses {
+		statuses[i] = models.MailboxUnknown
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.opts.Timeout)
+	defer cancel()
+
+	conn, host, err := v.dial(ctx, domain)
+	if err != nil {
+		return statuses
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return statuses
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return statuses
+	}
+	defer client.Close()
+
+	if err := client.Hello(v.opts.HeloName); err != nil {
+		return statuses
+	}
+
+	if err := client.Mail(v.opts.MailFrom); err != nil {
+		return statuses
+	}
+
+	// a server that accepts a made-up mailbox accepts everything
+	catchAll := rcptStatus(client.Rcpt(randomLocalPart()+"@"+domain)) == models.MailboxAccepted
+
+	utf8Allowed, _ := client.Extension("SMTPUTF8")
+
+	for i, address := range addresses {
+		if !isASCII(address) && !utf8Allowed {
+			continue
+		}
+
+		err := client.Rcpt(address)
+
+		statuses[i] = rcptStatus(err)
+		if statuses[i] == models.MailboxAccepted && catchAll {
+			statuses[i] = models.MailboxCatchAll
+		}
+
+		var protoErr *textproto.Error
+		if err != nil && !errors.As(err, &protoErr) {
+			// the connection is gone, later addresses stay unknown
+			break
+		}
+	}
+
+	// end the transaction without DATA so nothing is delivered
+	_ = client.Reset()
+	_ = client.Quit()
+
+	return statuses
+}
+
+// dial connects to the configured server, or to the domain's MX hosts in preference
+// order, falling back to the domain itself when it has no MX records
+func (v *SMTPVerifier) dial(ctx context.Context, domain string) (net.Conn, string, error) {
+	var dialer net.Dialer
+
+	if v.opts.Server != "" {
+		host, _, _ := net.SplitHostPort(v.opts.Server)
+		conn, err := dialer.DialContext(ctx, "tcp", v.opts.Server)
+
+		return conn, host, err
+	}
+
+	var hosts []string
+
+	records, err := v.resolver.LookupMX(ctx, domain)
+
+	switch {
+	case err != nil && !isNotFound(err):
+		return nil, "", err
+	case len(records) == 0:
+		hosts = []string{domain}
+	default:
+		for _, record := range records {
+			if host := strings.TrimSuffix(record.Host, "."); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+
+	err = models.ErrUndeliverable
+
+	for _, host := range hosts {
+		var conn net.Conn
+
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(v.opts.Port)))
+		if err == nil {
+			return conn, host, nil
+		}
+	}
+
+	return nil, "", err
+}
+
+// rcptStatus maps the reply to RCPT TO onto a mailbox status
+func rcptStatus(err error) models.MailboxStatus {
+	var protoErr *textproto.Error
+
+	switch {
+	case err == nil:
+		return models.MailboxAccepted
+	case errors.As(err, &protoErr) && protoErr.Code >= 500:
+		return models.MailboxRejected
+	default:
+		return models.MailboxUnknown
+	}
+}
+
+func randomLocalPart() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+
+	return "no-such-mailbox-" + hex.EncodeToString(buf)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,281 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// smtpBehavior decides how a fake SMTP server answers
+type smtpBehavior struct {
+	mailboxes  map[string]bool // accepted recipients
+	catchAll   bool
+	greylisted bool
+	stall      time.Duration // delay before the greeting
+}
+
+// fakeSMTPServer answers RCPT TO from a list of mailboxes and records every command,
+// so tests can check that no message is ever sent
+type fakeSMTPServer struct {
+	listener net.Listener
+	behavior smtpBehavior
+
+	mu       sync.Mutex
+	commands []string
+
+	active, peak atomic.Int32
+}
+
+func newFakeSMTPServer(t *testing.T, behavior smtpBehavior) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+
+	server := &fakeSMTPServer{listener: listener, behavior: behavior}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *fakeSMTPServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSMTPServer) sent(command string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.commands {
+		if strings.HasPrefix(c, command) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	if active := s.active.Add(1); active > s.peak.Load() {
+		s.peak.Store(active)
+	}
+	defer s.active.Add(-1)
+
+	time.Sleep(s.behavior.stall)
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 fake.test ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			_ = text.PrintfLine("250-fake.test\r\n250 SMTPUTF8")
+		case strings.HasPrefix(command, "MAIL FROM:"), strings.HasPrefix(command, "RSET"):
+			_ = text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			recipient := strings.ToLower(strings.Trim(line[len("RCPT TO:"):], "<> "))
+
+			switch {
+			case s.behavior.greylisted:
+				_ = text.PrintfLine("451 4.7.1 Try again later")
+			case s.behavior.catchAll || s.behavior.mailboxes[recipient]:
+				_ = text.PrintfLine("250 OK")
+			default:
+				_ = text.PrintfLine("550 5.1.1 No such user")
+			}
+		case strings.HasPrefix(command, "QUIT"):
+			_ = text.PrintfLine("221 Bye")
+
+			return
+		default:
+			_ = text.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+// stubResolver returns fixed MX records
+type stubResolver struct {
+	mx map[string][]*net.MX
+}
+
+func (r stubResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if records, ok := r.mx[name]; ok {
+		return records, nil
+	}
+
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r stubResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestSMTPVerifierVerifyEmails(t *testing.T) {
+	type dependencies struct {
+		server smtpBehavior
+	}
+
+	type args struct {
+		addresses []string
+		timeout   time.Duration
+	}
+
+	type expected struct {
+		statuses []models.MailboxStatus
+	}
+
+	tests := []struct {
+		name         string
+		dependencies dependencies
+		args         args
+		expected     expected
+	}{
+		{
+			name: "success/Accepted and rejected mailboxes",
+			dependencies: dependencies{
+				server: smtpBehavior{mailboxes: map[string]bool{"info@acme.test": true}},
+			},
+			args: args{addresses: []string{"info@acme.test", "gone@acme.test"}, timeout: 2 * time.Second},
+			expected: expected{
+				statuses: []models.MailboxStatus{models.MailboxAccepted, models.MailboxRejected},
+			},
+		},
+		{
+			name: "success/Catch-all server",
+			dependencies: dependencies{
+				server: smtpBehavior{catchAll: true},
+			},
+			args: args{addresses: []string{"info@acme.test", "anything@acme.test"}, timeout: 2 * time.Second},
+			expected: expected{
+				statuses: []models.MailboxStatus{models.MailboxCatchAll, models.MailboxCatchAll},
+			},
+		},
+		{
+			name: "success/EAI address on a server with SMTPUTF8",
+			dependencies: dependencies{
+				server: smtpBehavior{mailboxes: map[string]bool{"josé@acme.test": true}},
+			},
+			args: args{addresses: []string{"josé@acme.test"}, timeout: 2 * time.Second},
+			expected: expected{
+				statuses: []models.MailboxStatus{models.MailboxAccepted},
+			},
+		},
+		{
+			name: "error/Greylisting is unknown",
+			dependencies: dependencies{
+				server: smtpBehavior{greylisted: true},
+			},
+			args: args{addresses: []string{"info@acme.test"}, timeout: 2 * time.Second},
+			expected: expected{
+				statuses: []models.MailboxStatus{models.MailboxUnknown},
+			},
+		},
+		{
+			name: "error/Timeout is unknown",
+			dependencies: dependencies{
+				server: smtpBehavior{stall: 500 * time.Millisecond, mailboxes: map[string]bool{"info@acme.test": true}},
+			},
+			args: args{addresses: []string{"info@acme.test"}, timeout: 100 * time.Millisecond},
+			expected: expected{
+				statuses: []models.MailboxStatus{models.MailboxUnknown},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tc.dependencies.server)
+
+			opts := DefaultSMTPVerifyOptions()
+			opts.Server = server.addr()
+			opts.Timeout = tc.args.timeout
+
+			ranked := make([]models.RankedEmail, len(tc.args.addresses))
+			for i, address := range tc.args.addresses {
+				ranked[i] = models.RankedEmail{Address: address, ASCII: address}
+			}
+
+			NewSMTPVerifier(stubResolver{}, opts).VerifyEmails(context.Background(), ranked)
+
+			statuses := make([]models.MailboxStatus, len(ranked))
+			for i, email := range ranked {
+				statuses[i] = email.Mailbox
+			}
+
+			assert.Equal(t, tc.expected.statuses, statuses)
+			assert.False(t, server.sent("DATA"), "the probe must never send a message")
+		})
+	}
+}
+
+func TestSMTPVerifierMXHostsAndConcurrency(t *testing.T) {
+	server := newFakeSMTPServer(t, smtpBehavior{
+		stall:     50 * time.Millisecond,
+		mailboxes: map[string]bool{"info@a.test": true, "info@b.test": true, "info@c.test": true},
+	})
+
+	_, port, _ := net.SplitHostPort(server.addr())
+
+	resolver := stubResolver{mx: map[string][]*net.MX{
+		"a.test": {{Host: "unreachable.invalid.", Pref: 10}, {Host: "127.0.0.1.", Pref: 20}},
+		"b.test": {{Host: "127.0.0.1.", Pref: 10}},
+		"c.test": {{Host: "127.0.0.1.", Pref: 10}},
+	}}
+
+	opts := DefaultSMTPVerifyOptions()
+	opts.Port, _ = net.LookupPort("tcp", port)
+	opts.Timeout = 2 * time.Second
+	opts.Concurrency = 1
+
+	ranked := []models.RankedEmail{
+		{Address: "info@a.test", ASCII: "info@a.test"},
+		{Address: "info@b.test", ASCII: "info@b.test"},
+		{Address: "info@c.test", ASCII: "info@c.test"},
+		{Address: "info@parked.test", ASCII: "info@parked.test", Deliverability: models.Undeliverable},
+	}
+
+	NewSMTPVerifier(resolver, opts).VerifyEmails(context.Background(), ranked)
+
+	for _, email := range ranked[:3] {
+		assert.Equal(t, models.MailboxAccepted, email.Mailbox, email.Address)
+	}
+
+	assert.Empty(t, ranked[3].Mailbox, "undeliverable domains are not probed")
+	assert.Equal(t, int32(1), server.peak.Load(), "concurrency cap exceeded")
+}
@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// socialHosts maps the hosts of each network, without "www." or a country subdomain
+var socialHosts = map[string]models.SocialNetwork{
+	"linkedin.com":  models.LinkedIn,
+	"facebook.com":  models.Facebook,
+	"fb.com":        models.Facebook,
+	"twitter.com":   models.X,
+	"x.com":         models.X,
+	"instagram.com": models.Instagram,
+	"youtube.com":   models.YouTube,
+	"github.com":    models.GitHub,
+}
+
+// First path segments that are features of a network rather than profiles
+var socialReservedPaths = map[models.SocialNetwork]map[string]bool{
+	models.Facebook: {
+		"sharer": true, "sharer.php": true, "share": true, "share.php": true, "dialog": true,
+		"plugins": true, "tr": true, "login": true, "events": true, "groups": true, "hashtag": true,
+		"photo.php": true, "permalink.php": true, "story.php": true, "watch": true, "people": true,
+	},
+	models.X: {
+		"intent": true, "share": true, "home": true, "hashtag": true, "search": true, "i": true,
+		"login": true, "explore": true,
+	},
+	models.Instagram: {
+		"p": true, "reel": true, "reels": true, "explore": true, "accounts": true, "stories": true,
+	},
+	models.GitHub: {
+		"features": true, "about": true, "login": true, "sponsors": true, "marketplace": true,
+		"pricing": true, "topics": true, "site": true,
+	},
+}
+
+// socialProfile returns the canonical profile URL a link points to, if it is a profile
+// on one of the supported networks rather than a post, share button or feature page
+func socialProfile(rawURL string) (models.SocialProfile, bool) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return models.SocialProfile{}, false
+	}
+
+	network, ok := socialNetwork(target.Hostname())
+	if !ok {
+		return models.SocialProfile{}, false
+	}
+
+	var segments []string
+
+	for _, segment := range strings.Split(target.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	profileURL, ok := canonicalProfile(network, segments, target.Query())
+	if !ok {
+		return models.SocialProfile{}, false
+	}
+
+	return models.SocialProfile{Network: network, URL: profileURL}, true
+}
+
+// socialNetwork returns the network a host belongs to, ignoring www., mobile and country
+// subdomains such as de.linkedin.com or m.facebook.com
+func socialNetwork(host string) (models.SocialNetwork, bool) {
+	host = strings.ToLower(host)
+
+	network, ok := socialHosts[host]
+	for !ok && strings.Contains(host, ".") {
+		_, host, _ = strings.Cut(host, ".")
+		network, ok = socialHosts[host]
+	}
+
+	return network, ok
+}
+
+func canonicalProfile(network models.SocialNetwork, segments []string, query url.Values) (string, bool) {
+	if len(segments) == 0 || socialReservedPaths[network][strings.ToLower(segments[0])] {
+		return "", false
+	}
+
+	return profileCanonicalizers[network](segments, query)
+}
+
+// profileCanonicalizers build the canonical profile URL of each network from the path
+// segments of a link, the first of which is known not to be a reserved path
+var profileCanonicalizers = map[models.SocialNetwork]func(segments []string, query url.Values) (string, bool){
+	models.LinkedIn:  canonicalLinkedIn,
+	models.Facebook:  canonicalFacebook,
+	models.X:         canonicalX,
+	models.Instagram: canonicalInstagram,
+	models.YouTube:   canonicalYouTube,
+	models.GitHub:    canonicalGitHub,
+}
+
+func canonicalLinkedIn(segments []string, _ url.Values) (string, bool) {
+	kind := strings.ToLower(segments[0])
+	if len(segments) < 2 || (kind != "company" && kind != "in" && kind != "school" && kind != "showcase") {
+		return "", false
+	}
+
+	return "https://www.linkedin.com/" + kind + "/" + strings.ToLower(segments[1]), true
+}
+
+func canonicalFacebook(segments []string, query url.Values) (string, bool) {
+	page := strings.ToLower(segments[0])
+
+	switch {
+	case page == "profile.php" && query.Get("id") != "":
+		return "https://www.facebook.com/profile.php?id=" + url.QueryEscape(query.Get("id")), true
+	case page == "profile.php":
+		return "", false
+	case page == "pages" && len(segments) >= 2:
+		// facebook.com/pages/Name/123 keeps the name and numeric id
+		return "https://www.facebook.com/pages/" + strings.Join(segments[1:min(len(segments), 3)], "/"), true
+	}
+
+	return "https://www.facebook.com/" + page, true
+}
+
+func canonicalX(segments []string, _ url.Values) (string, bool) {
+	return "https://x.com/" + strings.TrimPrefix(strings.ToLower(segments[0]), "@"), true
+}
+
+func canonicalInstagram(segments []string, _ url.Values) (string, bool) {
+	return "https://www.instagram.com/" + strings.ToLower(segments[0]), true
+}
+
+func canonicalYouTube(segments []string, _ url.Values) (string, bool) {
+	kind := strings.ToLower(segments[0])
+
+	switch {
+	case strings.HasPrefix(kind, "@"):
+		return "https://www.youtube.com/" + kind, true
+	case (kind == "channel" || kind == "c" || kind == "user") && len(segments) >= 2:
+		// channel ids are case-sensitive
+		return "https://www.youtube.com/" + kind + "/" + segments[1], true
+	}
+
+	return "", false
+}
+
+func canonicalGitHub(segments []string, _ url.Values) (string, bool) {
+	owner := strings.ToLower(segments[0])
+	if owner == "orgs" && len(segments) >= 2 {
+		owner = strings.ToLower(segments[1])
+	}
+
+	return "https://github.com/" + owner, true
+}
+
+// socialProfilesFromLinks returns the profiles among the links of a page
+func socialProfilesFromLinks(links []pageLink, pageURL string) []models.SocialProfile {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var profiles []models.SocialProfile
+
+	for _, link := range links {
+		target, err := base.Parse(link.href)
+		if err != nil {
+			continue
+		}
+
+		if profile, ok := socialProfile(target.String()); ok {
+			profile.Source = models.SocialFromLink
+			profile.PageURL = pageURL
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// SelectSocialProfiles picks one profile per network: one the company declared in its
+// schema.org sameAs, then the one linked most often, or the first one found on a tie.
+func SelectSocialProfiles(profiles []models.SocialProfile) models.SocialProfiles {
+	counts := make(map[string]int)
+	declared := make(map[string]bool)
+
+	for _, profile := range profiles {
+		counts[profile.URL]++
+		declared[profile.URL] = declared[profile.URL] || profile.Source == models.SocialFromSchema
+	}
+
+	best := make(map[models.SocialNetwork]string)
+
+	for _, profile := range profiles {
+		current, ok := best[profile.Network]
+
+		switch {
+		case !ok:
+			best[profile.Network] = profile.URL
+		case declared[current] != declared[profile.URL]:
+			if declared[profile.URL] {
+				best[profile.Network] = profile.URL
+			}
+		case counts[profile.URL] > counts[current]:
+			best[profile.Network] = profile.URL
+		}
+	}
+
+	return models.SocialProfiles{
+		LinkedIn:  best[models.LinkedIn],
+		Facebook:  best[models.Facebook],
+		X:         best[models.X],
+		Instagram: best[models.Instagram],
+		YouTube:   best[models.YouTube],
+		GitHub:    best[models.GitHub],
+	}
+}
@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestSocialProfile(t *testing.T) {
+	type expected struct {
+		profile models.SocialProfile
+		ok      bool
+	}
+
+	tests := []struct {
+		name     string
+		rawURL   string
+		expected expected
+	}{
+		{
+			name:     "success/LinkedIn company page on a country subdomain",
+			rawURL:   "https://de.linkedin.com/company/Acme-GmbH/about/?trk=footer",
+			expected: expected{profile: models.SocialProfile{Network: models.LinkedIn, URL: "https://www.linkedin.com/company/acme-gmbh"}, ok: true},
+		},
+		{
+			name:     "success/Facebook page on the mobile site",
+			rawURL:   "http://m.facebook.com/AcmeInc/",
+			expected: expected{profile: models.SocialProfile{Network: models.Facebook, URL: "https://www.facebook.com/acmeinc"}, ok: true},
+		},
+		{
+			name:     "success/Facebook numeric profile",
+			rawURL:   "https://www.facebook.com/profile.php?id=100064&ref=page",
+			expected: expected{profile: models.SocialProfile{Network: models.Facebook, URL: "https://www.facebook.com/profile.php?id=100064"}, ok: true},
+		},
+		{
+			name:     "success/Twitter handle becomes an X profile",
+			rawURL:   "https://twitter.com/@AcmeInc",
+			expected: expected{profile: models.SocialProfile{Network: models.X, URL: "https://x.com/acmeinc"}, ok: true},
+		},
+		{
+			name:     "success/Instagram profile",
+			rawURL:   "https://www.instagram.com/acme.inc/?hl=en",
+			expected: expected{profile: models.SocialProfile{Network: models.Instagram, URL: "https://www.instagram.com/acme.inc"}, ok: true},
+		},
+		{
+			name:     "success/YouTube handle",
+			rawURL:   "https://youtube.com/@AcmeTV/videos",
+			expected: expected{profile: models.SocialProfile{Network: models.YouTube, URL: "https://www.youtube.com/@acmetv"}, ok: true},
+		},
+		{
+			name:     "success/YouTube channel id keeps its case",
+			rawURL:   "https://www.youtube.com/channel/UCabcDEF123",
+			expected: expected{profile: models.SocialProfile{Network: models.YouTube, URL: "https://www.youtube.com/channel/UCabcDEF123"}, ok: true},
+		},
+		{
+			name:     "success/GitHub repository links to its owner",
+			rawURL:   "https://github.com/Acme/widgets",
+			expected: expected{profile: models.SocialProfile{Network: models.GitHub, URL: "https://github.com/acme"}, ok: true},
+		},
+		{name: "error/Facebook share button", rawURL: "https://www.facebook.com/sharer/sharer.php?u=https://acme.test"},
+		{name: "error/Facebook photo", rawURL: "https://www.facebook.com/photo.php?fbid=123&set=a.456"},
+		{name: "error/Facebook post permalink", rawURL: "https://www.facebook.com/permalink.php?story_fbid=123&id=456"},
+		{name: "error/Facebook story", rawURL: "https://m.facebook.com/story.php?story_fbid=123&id=456"},
+		{name: "error/Facebook video", rawURL: "https://www.facebook.com/watch/?v=123"},
+		{name: "error/Facebook person", rawURL: "https://www.facebook.com/people/Jane-Doe/100064/"},
+		{name: "error/Tweet intent", rawURL: "https://twitter.com/intent/tweet?text=hi"},
+		{name: "error/Instagram post", rawURL: "https://www.instagram.com/p/Cx123/"},
+		{name: "error/YouTube video", rawURL: "https://www.youtube.com/watch?v=abc"},
+		{name: "error/LinkedIn feed", rawURL: "https://www.linkedin.com/feed/"},
+		{name: "error/Network home page", rawURL: "https://github.com/"},
+		{name: "error/Lookalike domain", rawURL: "https://notfacebook.com/acme"},
+		{name: "error/Not a web link", rawURL: "mailto:info@acme.test"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, ok := socialProfile(tc.rawURL)
+
+			assert.Equal(t, tc.expected.ok, ok)
+			assert.Equal(t, tc.expected.profile, profile)
+		})
+	}
+}
+
+func TestSelectSocialProfiles(t *testing.T) {
+	links := []pageLink{
+		{href: "https://twitter.com/acme_jobs"},
+		{href: "https://x.com/AcmeInc"},
+		{href: "https://twitter.com/acmeinc?lang=en"},
+		{href: "https://www.linkedin.com/company/acme"},
+		{href: "/about"},
+		{href: "https://www.facebook.com/sharer.php?u=x"},
+	}
+
+	profiles := socialProfilesFromLinks(links, "https://acme.test/")
+
+	assert.Len(t, profiles, 4)
+	assert.Equal(t, "https://acme.test/", profiles[0].PageURL)
+
+	assert.Equal(t, models.SocialProfiles{
+		LinkedIn: "https://www.linkedin.com/company/acme",
+		X:        "https://x.com/acmeinc",
+	}, SelectSocialProfiles(profiles))
+
+	// a profile the company declares in schema.org sameAs beats one linked more often
+	profiles = append(profiles, models.SocialProfile{
+		Network: models.X, URL: "https://x.com/acme_jobs", Source: models.SocialFromSchema,
+	})
+
+	assert.Equal(t, "https://x.com/acme_jobs", SelectSocialProfiles(profiles).X)
+}
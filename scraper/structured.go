@@ -0,0 +1,264 @@
+package scraper
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// schema.org properties read from structured data, lower-cased
+const (
+	schemaEmail           = "email"
+	schemaTelephone       = "telephone"
+	schemaSameAs          = "sameas"
+	schemaStreetAddress   = "streetaddress"
+	schemaAddressLocality = "addresslocality"
+	schemaAddressRegion   = "addressregion"
+	schemaPostalCode      = "postalcode"
+	schemaAddressCountry  = "addresscountry"
+)
+
+var schemaProperties = map[string]bool{
+	schemaEmail: true, schemaTelephone: true, schemaSameAs: true,
+	schemaStreetAddress: true, schemaAddressLocality: true, schemaAddressRegion: true,
+	schemaPostalCode: true, schemaAddressCountry: true,
+}
+
+// Prefixes RDFa and JSON-LD put before schema.org property names
+var schemaPrefixes = []string{"schema:", "http://schema.org/", "https://schema.org/"}
+
+// schemaProperty returns the lower-cased name of a schema.org property read from an
+// itemprop or property attribute, or "" when it is not one the scanner collects.
+// Other vocabularies such as Open Graph ("og:email") are ignored.
+func schemaProperty(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, prefix := range schemaPrefixes {
+		name = strings.TrimPrefix(name, prefix)
+	}
+
+	if !schemaProperties[name] {
+		return ""
+	}
+
+	return name
+}
+
+// structuredAttributes are the attributes of a tag that carry microdata or RDFa
+type structuredAttributes struct {
+	properties []string
+	content    string
+	hasContent bool
+	href       string
+	isItem     bool // the element is itself an item, so its text is not a value
+	isAddress  bool
+	isJSONLD   bool
+}
+
+func readStructuredAttributes(token html.Token) structuredAttributes {
+	var attrs structuredAttributes
+
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "itemprop", "property":
+			for _, name := range strings.Fields(attr.Val) {
+				if property := schemaProperty(name); property != "" {
+					attrs.properties = append(attrs.properties, property)
+				}
+			}
+		case "itemtype", "typeof":
+			attrs.isItem = true
+			attrs.isAddress = attrs.isAddress || strings.Contains(strings.ToLower(attr.Val), "postaladdress")
+		case "itemscope":
+			attrs.isItem = true
+		case "content":
+			attrs.content, attrs.hasContent = attr.Val, true
+		case "href":
+			if token.DataAtom == atom.A || token.DataAtom == atom.Link {
+				attrs.href = attr.Val
+			}
+		case "type":
+			attrs.isJSONLD = token.DataAtom == atom.Script &&
+				strings.EqualFold(strings.TrimSpace(attr.Val), "application/ld+json")
+		}
+	}
+
+	return attrs
+}
+
+// startStructuredTag reads schema.org microdata (itemprop, itemtype) and RDFa (property,
+// typeof) from a tag, and starts buffering JSON-LD scripts. A property without a content
+// or href value takes the element's text.
+func (s *pageScanner) startStructuredTag(token html.Token) {
+	attrs := readStructuredAttributes(token)
+
+	if attrs.isJSONLD {
+		s.jsonLD = &strings.Builder{}
+	}
+
+	if attrs.isAddress {
+		s.startAddress(models.AddressFromSchema)
+	}
+
+	for _, property := range attrs.properties {
+		switch {
+		case attrs.hasContent:
+			s.schemaValue(property, attrs.content)
+		case attrs.href != "":
+			s.schemaValue(property, attrs.href)
+		case !attrs.isItem:
+			s.itemProp = property
+		}
+	}
+}
+
+// itemText takes text as the value of a pending microdata or RDFa property. It returns
+// the property, or "" when there was none, so that the text is not scanned again for the
+// same kind of value.
+func (s *pageScanner) itemText(text string) string {
+	property := s.itemProp
+	if property == "" || strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	s.itemProp = ""
+	s.schemaValue(property, text)
+
+	if property == schemaTelephone {
+		s.phoneContext = ""
+	}
+
+	return property
+}
+
+// schemaValue records the value of a schema.org property
+func (s *pageScanner) schemaValue(property, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return
+	}
+
+	switch property {
+	case schemaEmail:
+		if found := emailsFromMailto(value, models.SourceSchema); len(found) > 0 {
+			s.candidates = append(s.candidates, found...)
+
+			return
+		}
+
+		s.candidates = append(s.candidates, findEmails(value, models.SourceSchema)...)
+	case schemaTelephone:
+		if tel := phonesFromTel(value); len(tel) > 0 {
+			value = tel[0].Raw
+		}
+
+		s.phones = append(s.phones, models.PhoneCandidate{Raw: value, Source: models.PhoneFromSchema})
+	case schemaSameAs:
+		if profile, ok := socialProfile(value); ok {
+			profile.Source = models.SocialFromSchema
+			s.profiles = append(s.profiles, profile)
+		}
+	default:
+		s.setAddressProperty(property, value, models.AddressFromSchema)
+	}
+}
+
+// endJSONLD parses a JSON-LD script once it is complete
+func (s *pageScanner) endJSONLD() {
+	if s.jsonLD == nil {
+		return
+	}
+
+	var document any
+	if err := json.Unmarshal([]byte(s.jsonLD.String()), &document); err == nil {
+		s.walkJSONLD(document)
+	}
+
+	s.jsonLD = nil
+}
+
+// walkJSONLD records the schema.org values of every node in a JSON-LD document,
+// including those nested in @graph, contactPoint and address
+func (s *pageScanner) walkJSONLD(node any) {
+	switch value := node.(type) {
+	case []any:
+		for _, child := range value {
+			s.walkJSONLD(child)
+		}
+	case map[string]any:
+		if isPostalAddress(value) {
+			s.startAddress(models.AddressFromSchema)
+
+			for key, child := range value {
+				if values := jsonStrings(child); len(values) > 0 {
+					s.setAddressProperty(strings.ToLower(key), values[0], models.AddressFromSchema)
+				}
+			}
+
+			s.endAddress()
+
+			return
+		}
+
+		// sorted so values are found in the same order on every run
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if property := schemaProperty(key); property != "" {
+				for _, text := range jsonStrings(value[key]) {
+					s.schemaValue(property, text)
+				}
+			}
+
+			s.walkJSONLD(value[key])
+		}
+	}
+}
+
+// isPostalAddress tells whether a JSON-LD node is a schema.org PostalAddress
+func isPostalAddress(node map[string]any) bool {
+	for _, kind := range jsonStrings(node["@type"]) {
+		if strings.EqualFold(kind, "PostalAddress") {
+			return true
+		}
+	}
+
+	_, hasStreet := node["streetAddress"]
+	_, hasPostalCode := node["postalCode"]
+
+	return hasStreet || hasPostalCode
+}
+
+// jsonStrings returns the text of a JSON-LD value: a string, the strings of an array, or
+// the name of a node such as {"@type": "Country", "name": "DE"}
+func jsonStrings(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var texts []string
+
+		for _, child := range value {
+			if text, ok := child.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+
+		return texts
+	case map[string]any:
+		if name, ok := value["name"].(string); ok {
+			return []string{name}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestPageScannerStructuredData(t *testing.T) {
+	type expected struct {
+		emails     []string
+		textEmails []string // found in the text outside schema.org values
+		phones     []models.PhoneCandidate
+		profiles   []models.SocialProfile
+		addresses  []models.PostalAddress
+	}
+
+	tests := []struct {
+		name     string
+		document string
+		expected expected
+	}{
+		{
+			name: "JSON-LD organization in a graph",
+			document: `<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [{
+  "@type": "Organization", "name": "Acme GmbH",
+  "email": "mailto:info@acme.de",
+  "telephone": ["+49 30 1234567", "+49 30 7654321"],
+  "sameAs": ["https://www.linkedin.com/company/acme/", "https://acme.de/blog"],
+  "address": {"@type": "PostalAddress", "streetAddress": "Hauptstr. 1",
+    "addressLocality": "Berlin", "postalCode": "10115",
+    "addressCountry": {"@type": "Country", "name": "DE"}}
+}]}
+</script>`,
+			expected: expected{
+				emails: []string{"info@acme.de"},
+				phones: []models.PhoneCandidate{
+					{Raw: "+49 30 1234567", Source: models.PhoneFromSchema},
+					{Raw: "+49 30 7654321", Source: models.PhoneFromSchema},
+				},
+				profiles: []models.SocialProfile{
+					{Network: models.LinkedIn, URL: "https://www.linkedin.com/company/acme", Source: models.SocialFromSchema},
+				},
+				addresses: []models.PostalAddress{
+					{Street: "Hauptstr. 1", City: "Berlin", PostalCode: "10115", Country: "DE"},
+				},
+			},
+		},
+		{
+			name:     "Invalid JSON-LD is ignored",
+			document: `<script type="application/ld+json">{"email": "info@acme.de",</script>`,
+		},
+		{
+			name: "Microdata",
+			document: `<div itemscope itemtype="https://schema.org/LocalBusiness">
+  <a itemprop="email" href="mailto:office@acme.de">Write to us</a>
+  <span itemprop="telephone">030 5555555</span>
+  <link itemprop="sameAs" href="https://twitter.com/acme">
+  <div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress">
+    <span itemprop="streetAddress">Hauptstr. 1</span>,
+    <span itemprop="postalCode">10115</span> <span itemprop="addressLocality">Berlin</span>
+  </div>
+</div>`,
+			expected: expected{
+				emails:   []string{"office@acme.de"},
+				phones:   []models.PhoneCandidate{{Raw: "030 5555555", Source: models.PhoneFromSchema}},
+				profiles: []models.SocialProfile{{Network: models.X, URL: "https://x.com/acme", Source: models.SocialFromSchema}},
+				addresses: []models.PostalAddress{
+					{Street: "Hauptstr. 1", City: "Berlin", PostalCode: "10115"},
+				},
+			},
+		},
+		{
+			name: "RDFa",
+			document: `<div vocab="https://schema.org/" typeof="Organization">
+  <span property="email">sales@acme.de</span>
+  <meta property="og:email" content="press@acme.de">
+  <div property="address" typeof="PostalAddress">
+    <span property="schema:streetAddress">Ringstr. 5</span>
+    <span property="addressLocality">Wien</span>
+    <meta property="addressCountry" content="AT">
+  </div>
+</div>`,
+			expected: expected{
+				emails:    []string{"sales@acme.de"},
+				addresses: []models.PostalAddress{{Street: "Ringstr. 5", City: "Wien", Country: "AT"}},
+			},
+		},
+		{
+			name: "Repeated address parts start a new address",
+			document: `<p><span itemprop="streetAddress">Hauptstr. 1</span> <span itemprop="addressLocality">Berlin</span></p>
+<p><span itemprop="streetAddress">Marienplatz 2</span> <span itemprop="addressLocality">München</span></p>`,
+			expected: expected{
+				addresses: []models.PostalAddress{
+					{Street: "Hauptstr. 1", City: "Berlin"},
+					{Street: "Marienplatz 2", City: "München"},
+				},
+			},
+		},
+		{
+			name:     "Property without text takes no value",
+			document: `<span itemprop="email"></span><p>Call us at sales@acme.de</p>`,
+			expected: expected{textEmails: []string{"sales@acme.de"}},
+		},
+		{
+			name:     "Text taken as an email property is not found again",
+			document: `<p itemprop="email">info@acme.de</p><p>or press@acme.de</p>`,
+			expected: expected{emails: []string{"info@acme.de"}, textEmails: []string{"press@acme.de"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner := &pageScanner{}
+			assert.NoError(t, scanner.scan(strings.NewReader(tc.document)))
+
+			var emails, textEmails []string
+
+			for _, candidate := range scanner.candidates {
+				if candidate.Source == models.SourceSchema {
+					emails = append(emails, candidate.Address)
+				} else if candidate.Source == models.SourceText {
+					textEmails = append(textEmails, candidate.Address)
+				}
+			}
+
+			var addresses []models.PostalAddress
+
+			for _, candidate := range scanner.addresses {
+				assert.Equal(t, models.AddressFromSchema, candidate.Source)
+				addresses = append(addresses, candidate.Address)
+			}
+
+			assert.Equal(t, tc.expected.emails, emails)
+			assert.Equal(t, tc.expected.textEmails, textEmails)
+			assert.Equal(t, tc.expected.phones, scanner.phones)
+			assert.Equal(t, tc.expected.profiles, scanner.profiles)
+			assert.Equal(t, tc.expected.addresses, addresses)
+		})
+	}
+}
+
+func TestSchemaProperty(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "telephone", expected: schemaTelephone},
+		{name: "sameAs", expected: schemaSameAs},
+		{name: "schema:email", expected: schemaEmail},
+		{name: "http://schema.org/postalCode", expected: schemaPostalCode},
+		{name: "og:email", expected: ""},
+		{name: "name", expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, schemaProperty(tc.name))
+		})
+	}
+}
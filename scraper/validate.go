@@ -0,0 +1,128 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+// RFC 5321 section 4.5.3.1 limits
+const (
+	maxLocalPartLength = 64
+	maxDomainLength    = 255
+	maxAddressLength   = 254 // a forward-path of 256 octets less the angle brackets
+	maxLabelLength     = 63
+)
+
+var (
+	// leading percent-escapes and URL-encoded mailto: schemes left over from URL-encoded
+	// text, e.g. "%20info@", "%40info@" or "mailto%3Ainfo@" from "?ref=mailto%3Ainfo@"
+	percentEscapePrefixRegex = regexp.MustCompile(`(?i)^(?:%[0-9a-f]{2}|mailto%3a)+`)
+
+	// RFC 5322 dot-atom text; UTF-8 is allowed as well for EAI addresses (RFC 6532)
+	atextRegex = regexp.MustCompile(`^[\p{L}\p{M}\p{N}!#$%&'*+/=?^_{|}~-]+$`)
+
+	// an LDH label of an ASCII (IDNA) domain
+	domainLabelRegex = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+
+	// top-level domains are letters, or an IDNA "xn--" label
+	topLevelDomainRegex = regexp.MustCompile(`^(?:[a-z]{2,}|xn--[a-z0-9-]+)$`)
+)
+
+// Domains reserved for documentation (RFC 2606) or used as "your domain here" in templates
+var placeholderDomains = map[string]bool{
+	"example.com":     true,
+	"example.net":     true,
+	"example.org":     true,
+	"example.edu":     true,
+	"domain.com":      true,
+	"yourdomain.com":  true,
+	"yoursite.com":    true,
+	"yourcompany.com": true,
+	"company.com":     true,
+	"website.com":     true,
+	"mysite.com":      true,
+}
+
+// Local parts that only ever appear in form hints such as "your@email.com", when
+// paired with a generic mail domain
+var placeholderLocalParts = map[string]bool{
+	"your": true, "yourname": true, "youremail": true, "your.name": true, "you": true,
+	"name": true, "user": true, "username": true, "email": true, "someone": true,
+	"firstname": true, "firstname.lastname": true, "first.last": true,
+	"john.doe": true, "johndoe": true, "jane.doe": true, "janedoe": true,
+}
+
+var genericMailDomains = map[string]bool{
+	"email.com": true, "mail.com": true, "gmail.com": true, "yahoo.com": true,
+	"hotmail.com": true, "outlook.com": true,
+}
+
+// cleanEmail strips what the extraction regex picks up around an address: percent-escape
+// and mailto%3A prefixes from URL-encoded text and surrounding punctuation such as dots
+// or quotes.
+func cleanEmail(raw string) string {
+	address := strings.TrimSpace(raw)
+	address = percentEscapePrefixRegex.ReplaceAllString(address, "")
+
+	return strings.Trim(address, `.,;:'"()[]<>{}`)
+}
+
+// validateEmail checks an address in ASCII form (see normalizeEmail) against the RFC 5321
+// length limits and the RFC 5322 dot-atom syntax, and rejects placeholder addresses.
+// Errors wrap models.ErrInvalidEmail or models.ErrPlaceholderEmail.
+func validateEmail(address string) error {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return fmt.Errorf("%w: %q has no @", models.ErrInvalidEmail, address)
+	}
+
+	local, domain := address[:at], strings.ToLower(address[at+1:])
+
+	switch {
+	case utf8.RuneCountInString(address) > maxAddressLength:
+		return fmt.Errorf("%w: %q is too long", models.ErrInvalidEmail, address)
+	case local == "" || len(local) > maxLocalPartLength:
+		return fmt.Errorf("%w: %q has an invalid local part length", models.ErrInvalidEmail, address)
+	case len(domain) > maxDomainLength:
+		return fmt.Errorf("%w: %q has a domain that is too long", models.ErrInvalidEmail, address)
+	}
+
+	for _, atom := range strings.Split(local, ".") {
+		if !atextRegex.MatchString(atom) {
+			return fmt.Errorf("%w: %q has an invalid local part", models.ErrInvalidEmail, address)
+		}
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 || !topLevelDomainRegex.MatchString(labels[len(labels)-1]) {
+		return fmt.Errorf("%w: %q has an invalid top-level domain", models.ErrInvalidEmail, address)
+	}
+
+	for _, label := range labels {
+		if len(label) > maxLabelLength || !domainLabelRegex.MatchString(label) {
+			return fmt.Errorf("%w: %q has an invalid domain label", models.ErrInvalidEmail, address)
+		}
+	}
+
+	if isPlaceholderEmail(strings.ToLower(local), domain) {
+		return fmt.Errorf("%w: %s", models.ErrPlaceholderEmail, address)
+	}
+
+	return nil
+}
+
+func isPlaceholderEmail(local, domain string) bool {
+	for candidate := domain; candidate != ""; {
+		if placeholderDomains[candidate] {
+			return true
+		}
+
+		_, candidate, _ = strings.Cut(candidate, ".")
+	}
+
+	return placeholderLocalParts[local] && genericMailDomains[domain]
+}
@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestCleanEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{name: "Trailing dot", raw: "info@acme.com.", expected: "info@acme.com"},
+		{name: "Leading dots", raw: "...info@acme.com", expected: "info@acme.com"},
+		{name: "URL-encoded @ prefix", raw: "%40info@acme.com", expected: "info@acme.com"},
+		{name: "URL-encoded space prefixes", raw: "%20%20info@acme.com", expected: "info@acme.com"},
+		{name: "URL-encoded mailto: prefix", raw: "mailto%3Ainfo@acme.com", expected: "info@acme.com"},
+		{name: "URL-encoded mailto: prefix in upper case", raw: "%22MAILTO%3ainfo@acme.com", expected: "info@acme.com"},
+		{name: "Local part starting with mailto is kept", raw: "mailtoinfo@acme.com", expected: "mailtoinfo@acme.com"},
+		{name: "Surrounding quotes and brackets", raw: `"<info@acme.com>",`, expected: "info@acme.com"},
+		{name: "Clean address is unchanged", raw: "first.last+tag@acme.com", expected: "first.last+tag@acme.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cleanEmail(tc.raw))
+		})
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected error
+	}{
+		{name: "success/Plain address", address: "info@acme.com"},
+		{name: "success/Dot-atom with special characters", address: "o'brien+sales@mail.acme.co.uk"},
+		{name: "success/IDNA domain", address: "info@xn--bcher-kva.xn--p1ai"},
+		{name: "success/EAI local part", address: "josé@acme.com"},
+		{name: "success/Real address on a generic mail domain", address: "acme.sales@gmail.com"},
+		{name: "error/Missing @", address: "info.acme.com", expected: models.ErrInvalidEmail},
+		{name: "error/One-letter top-level domain", address: "name@domain.c", expected: models.ErrInvalidEmail},
+		{name: "error/Numeric top-level domain", address: "name@10.0.0.1", expected: models.ErrInvalidEmail},
+		{name: "error/Consecutive dots in local part", address: "first..last@acme.com", expected: models.ErrInvalidEmail},
+		{name: "error/Trailing dot in local part", address: "info.@acme.com", expected: models.ErrInvalidEmail},
+		{name: "error/Hyphen at label edge", address: "info@-acme.com", expected: models.ErrInvalidEmail},
+		{name: "error/Empty domain label", address: "info@acme..com", expected: models.ErrInvalidEmail},
+		{name: "error/Local part too long", address: strings.Repeat("a", 65) + "@acme.com", expected: models.ErrInvalidEmail},
+		{name: "error/Domain label too long", address: "info@" + strings.Repeat("a", 64) + ".com", expected: models.ErrInvalidEmail},
+		{name: "error/Documentation domain", address: "user@example.com", expected: models.ErrPlaceholderEmail},
+		{name: "error/Documentation subdomain", address: "sales@mail.example.org", expected: models.ErrPlaceholderEmail},
+		{name: "error/Template domain", address: "info@yourdomain.com", expected: models.ErrPlaceholderEmail},
+		{name: "error/Form hint", address: "Your@Email.com", expected: models.ErrPlaceholderEmail},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateEmail(tc.address)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Businge931/company-email-scraper/models"
+	"github.com/Businge931/company-email-scraper/scraper"
+)
+
+// JobStatus is the progress of a batch job.
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"  // waiting for a worker
+	JobRunning JobStatus = "running" // companies are being looked up
+	JobDone    JobStatus = "done"    // every company was looked up
+)
+
+// job is a batch of companies looked up one after the other in the background. Its fields
+// are guarded by the server's mutex.
+type job struct {
+	id       string
+	names    []string
+	country  string
+	results  []scraper.CompanyRecord // one per company looked up, in the order of names
+	status   JobStatus
+	created  time.Time
+	finished time.Time
+}
+
+type createJobRequest struct {
+	Names   []string `json:"names"`
+	Country string   `json:"country"`
+}
+
+type jobResponse struct {
+	ID         string     `json:"id"`
+	Status     JobStatus  `json:"status"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type jobResultsResponse struct {
+	ID      string                  `json:"id"`
+	Status  JobStatus               `json:"status"`
+	Results []scraper.CompanyRecord `json:"results"`
+}
+
+func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var request createJobRequest
+	if err := decodeRequest(w, r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	var names []string
+
+	for _, name := range request.Names {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	switch {
+	case len(names) == 0:
+		writeError(w, http.StatusBadRequest, models.ErrMissingCompanyName)
+
+		return
+	case len(names) > s.opts.MaxBatch:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %d, at most %d", models.ErrBatchTooLarge, len(names), s.opts.MaxBatch))
+
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	created := &job{id: id, names: names, country: strings.TrimSpace(request.Country), status: JobQueued, created: time.Now()}
+
+	s.mu.Lock()
+	s.pruneJobs()
+
+	if s.unfinishedJobs() >= s.opts.MaxJobs {
+		s.mu.Unlock()
+		writeError(w, http.StatusTooManyRequests, fmt.Errorf("%w: at most %d", models.ErrTooManyJobs, s.opts.MaxJobs))
+
+		return
+	}
+
+	s.jobs[id] = created
+	response := created.response()
+	s.mu.Unlock()
+
+	go s.run(created)
+
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, response)
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrJobNotFound)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, found.response())
+}
+
+func (s *Server) handleGetJobResults(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, models.ErrJobNotFound)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobResultsResponse{
+		ID:      found.id,
+		Status:  found.status,
+		Results: append([]scraper.CompanyRecord{}, found.results...),
+	})
+}
+
+// run looks up the companies of a job in order, each one taking a worker slot so that
+// jobs started together share the workers with single lookups. A job outlives the request
+// that started it, so its lookups are only cancelled when the server is closed, which
+// ends the job with the companies looked up so far.
+func (s *Server) run(current *job) {
+	for _, name := range current.names {
+		if !s.takeWorker() {
+			break
+		}
+
+		s.mu.Lock()
+		current.status = JobRunning
+		s.mu.Unlock()
+
+		company, err := s.lookup(s.ctx, name, current.country)
+		<-s.workers
+
+		s.mu.Lock()
+		current.results = append(current.results, scraper.NewCompanyRecord(company, err))
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	current.status = JobDone
+	current.finished = time.Now()
+	s.mu.Unlock()
+}
+
+// takeWorker waits for a worker slot, giving up once the server is closed
+func (s *Server) takeWorker() bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// pruneJobs forgets the jobs that finished longer ago than the TTL; the caller holds the mutex
+func (s *Server) pruneJobs() {
+	for id, stored := range s.jobs {
+		if stored.status == JobDone && time.Since(stored.finished) > s.opts.JobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// unfinishedJobs counts the jobs queued or running; the caller holds the mutex
+func (s *Server) unfinishedJobs() int {
+	unfinished := 0
+
+	for _, stored := range s.jobs {
+		if stored.status != JobDone {
+			unfinished++
+		}
+	}
+
+	return unfinished
+}
+
+func (j *job) response() jobResponse {
+	response := jobResponse{
+		ID:        j.id,
+		Status:    j.status,
+		Total:     len(j.names),
+		Completed: len(j.results),
+		CreatedAt: j.created,
+	}
+
+	if !j.finished.IsZero() {
+		finished := j.finished
+		response.FinishedAt = &finished
+	}
+
+	return response
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+
+	return hex.EncodeToString(id), nil
+}
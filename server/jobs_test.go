@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	lookup := stubLookup(map[string]models.CompanyResult{"Acme": acme})
+
+	api := New(func(ctx context.Context, companyName, country string) (models.CompanyResult, error) {
+		<-release
+
+		assert.Equal(t, "DE", country, "every lookup of the job gets its country")
+
+		return lookup(ctx, companyName, country)
+	}, DefaultOptions())
+	handler := api.Handler()
+
+	recorder := httptest.NewRecorder()
+	body := `{"names": ["Acme", "", "Nobody"], "country": "DE"}`
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var created jobResponse
+
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	assert.Equal(t, "/jobs/"+created.ID, recorder.Header().Get("Location"))
+	assert.Equal(t, JobQueued, created.Status)
+	assert.Equal(t, 2, created.Total)
+
+	getJob := func() jobResponse {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs/"+created.ID, nil))
+
+		var progress jobResponse
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &progress))
+
+		return progress
+	}
+
+	release <- struct{}{}
+
+	assert.Eventually(t, func() bool { return getJob().Completed == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, JobRunning, getJob().Status)
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return getJob().Status == JobDone }, time.Second, time.Millisecond)
+	assert.NotNil(t, getJob().FinishedAt)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs/"+created.ID+"/results", nil))
+
+	var results jobResultsResponse
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &results))
+	assert.Equal(t, JobDone, results.Status)
+
+	if assert.Len(t, results.Results, 2) {
+		assert.Equal(t, "info@acme.test", results.Results[0].Email)
+		assert.Empty(t, results.Results[0].Error)
+		assert.Equal(t, "Nobody", results.Results[1].Name)
+		assert.Contains(t, results.Results[1].Error, models.ErrNoUsableSite.Error())
+	}
+}
+
+func TestCloseCancelsJobs(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Workers = 1
+
+	api := New(func(ctx context.Context, companyName, _ string) (models.CompanyResult, error) {
+		<-ctx.Done()
+
+		return models.CompanyResult{Name: companyName}, ctx.Err()
+	}, opts)
+	handler := api.Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"names": ["Acme", "Globex", "Initech"]}`)))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var created jobResponse
+
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+
+	getResults := func() jobResultsResponse {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs/"+created.ID+"/results", nil))
+
+		var results jobResultsResponse
+
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &results))
+
+		return results
+	}
+
+	assert.Eventually(t, func() bool { return getResults().Status == JobRunning }, time.Second, time.Millisecond)
+
+	api.Close()
+
+	assert.Eventually(t, func() bool { return getResults().Status == JobDone }, time.Second, time.Millisecond)
+
+	results := getResults()
+	if assert.Len(t, results.Results, 1, "the companies after the cancelled lookup are not looked up") {
+		assert.Contains(t, results.Results[0].Error, context.Canceled.Error())
+	}
+
+	assert.Empty(t, api.workers, "the worker slot is given back")
+}
+
+func TestHandleCreateJob(t *testing.T) {
+	type args struct {
+		body     string
+		maxBatch int
+	}
+
+	type expected struct {
+		status int
+		err    error
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name:     "success/Job accepted",
+			args:     args{body: `{"names": ["Acme", "Globex"]}`, maxBatch: 2},
+			expected: expected{status: http.StatusAccepted},
+		},
+		{
+			name:     "error/No names",
+			args:     args{body: `{"names": [" "]}`, maxBatch: 2},
+			expected: expected{status: http.StatusBadRequest, err: models.ErrMissingCompanyName},
+		},
+		{
+			name:     "error/Too many names",
+			args:     args{body: `{"names": ["Acme", "Globex", "Initech"]}`, maxBatch: 2},
+			expected: expected{status: http.StatusBadRequest, err: models.ErrBatchTooLarge},
+		},
+		{
+			name:     "error/Malformed body",
+			args:     args{body: `["Acme"]`, maxBatch: 2},
+			expected: expected{status: http.StatusBadRequest, err: models.ErrInvalidRequestBody},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MaxBatch = tc.args.maxBatch
+
+			api := New(stubLookup(nil), opts)
+
+			recorder := httptest.NewRecorder()
+			api.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(tc.args.body)))
+
+			assert.Equal(t, tc.expected.status, recorder.Code)
+
+			if tc.expected.err != nil {
+				var response errorResponse
+
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Contains(t, response.Error, tc.expected.err.Error())
+			}
+		})
+	}
+}
+
+func TestHandleCreateJobLimit(t *testing.T) {
+	release := make(chan struct{})
+	lookup := stubLookup(map[string]models.CompanyResult{"Acme": acme})
+
+	opts := DefaultOptions()
+	opts.MaxJobs = 1
+
+	api := New(func(ctx context.Context, companyName, country string) (models.CompanyResult, error) {
+		<-release
+
+		return lookup(ctx, companyName, country)
+	}, opts)
+
+	createJob := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		api.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"names": ["Acme"]}`)))
+
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusAccepted, createJob().Code)
+
+	recorder := createJob()
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), models.ErrTooManyJobs.Error())
+
+	close(release)
+
+	// a finished job no longer counts against the limit
+	assert.Eventually(t, func() bool { return createJob().Code == http.StatusAccepted }, time.Second, time.Millisecond)
+}
+
+func TestHandleGetJobNotFound(t *testing.T) {
+	api := New(stubLookup(nil), DefaultOptions())
+
+	for _, path := range []string{"/jobs/missing", "/jobs/missing/results"} {
+		recorder := httptest.NewRecorder()
+		api.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.JSONEq(t, `{"error":"job not found"}`, recorder.Body.String())
+	}
+}
+
+func TestPruneJobs(t *testing.T) {
+	opts := DefaultOptions()
+	opts.JobTTL = time.Minute
+
+	api := New(stubLookup(nil), opts)
+	api.jobs["old"] = &job{id: "old", status: JobDone, finished: time.Now().Add(-time.Hour)}
+	api.jobs["recent"] = &job{id: "recent", status: JobDone, finished: time.Now()}
+	api.jobs["running"] = &job{id: "running", status: JobRunning}
+
+	api.pruneJobs()
+
+	assert.NotContains(t, api.jobs, "old")
+	assert.Contains(t, api.jobs, "recent")
+	assert.Contains(t, api.jobs, "running")
+}
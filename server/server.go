@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/Businge931/company-email-scraper/models"
+	"github.com/Businge931/company-email-scraper/scraper"
+)
+
+// LookupFunc finds the contact details of one company by name, giving up once ctx is done.
+// The country, which may be empty, is the ISO code national phone numbers are read in.
+type LookupFunc func(ctx context.Context, companyName, country string) (models.CompanyResult, error)
+
+// Options bounds the work the server takes on.
+type Options struct {
+	Workers  int           // lookups run at the same time, single lookups and jobs alike
+	MaxBatch int           // companies accepted in one job
+	MaxJobs  int           // jobs queued or running at the same time
+	JobTTL   time.Duration // how long a finished job and its results are kept
+}
+
+// Largest request body read, enough for a job of MaxBatch long company names
+const maxRequestBytes = 1 << 20
+
+// DefaultOptions runs two lookups at a time, accepts ten unfinished jobs and keeps
+// finished jobs for an hour.
+func DefaultOptions() Options {
+	return Options{
+		Workers:  2,
+		MaxBatch: 100,
+		MaxJobs:  10,
+		JobTTL:   time.Hour,
+	}
+}
+
+func getOptions() Options {
+	opts := DefaultOptions()
+
+	if viper.IsSet("server.workers") {
+		opts.Workers = viper.GetInt("server.workers")
+	}
+
+	if viper.IsSet("server.max_batch") {
+		opts.MaxBatch = viper.GetInt("server.max_batch")
+	}
+
+	if viper.IsSet("server.max_jobs") {
+		opts.MaxJobs = viper.GetInt("server.max_jobs")
+	}
+
+	if viper.IsSet("server.job_ttl") {
+		opts.JobTTL = viper.GetDuration("server.job_ttl")
+	}
+
+	return opts
+}
+
+// Server answers contact lookups over HTTP, one company at a time or as batch jobs that
+// run in the background.
+type Server struct {
+	lookup  LookupFunc
+	opts    Options
+	workers chan struct{} // one slot per lookup in progress
+
+	// ctx is the parent of the lookups of jobs, which outlive the requests that start them
+	ctx  context.Context
+	stop context.CancelFunc
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// New returns a server that looks companies up with lookup.
+func New(lookup LookupFunc, opts Options) *Server {
+	ctx, stop := context.WithCancel(context.Background())
+
+	return &Server{
+		lookup:  lookup,
+		opts:    opts,
+		workers: make(chan struct{}, max(opts.Workers, 1)),
+		ctx:     ctx,
+		stop:    stop,
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Close cancels the lookups of running jobs, which finish with the results found so far.
+// Single lookups end with their requests.
+func (s *Server) Close() {
+	s.stop()
+}
+
+// NewFromConfig returns a server that searches and crawls with the given clients and
+// checks, the same way as the batch run, bounded by the "server" configuration. Each
+// lookup keeps its own cookies.
+func NewFromConfig(searchClient scraper.HTTPClient, fetchClient *scraper.FetchClient, checks scraper.EmailChecks) *Server {
+	return New(func(ctx context.Context, companyName, country string) (models.CompanyResult, error) {
+		return scraper.LookupCompany(ctx, searchClient, fetchClient.ForLookup(), checks, companyName, country)
+	}, getOptions())
+}
+
+// Handler routes the API:
+//
+//	POST /lookup             looks up one company and answers with its contacts
+//	POST /jobs               starts a batch job for a list of companies
+//	GET  /jobs/{id}          reports the progress of a job
+//	GET  /jobs/{id}/results  returns the contacts found so far, in the order of the list
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /lookup", s.handleLookup)
+	mux.HandleFunc("POST /jobs", s.handleCreateJob)
+	mux.HandleFunc("GET /jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("GET /jobs/{id}/results", s.handleGetJobResults)
+
+	return mux
+}
+
+type lookupRequest struct {
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	var request lookupRequest
+	if err := decodeRequest(w, r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, models.ErrMissingCompanyName)
+
+		return
+	}
+
+	// single lookups take a worker slot like those of jobs, waiting no longer than the client
+	select {
+	case s.workers <- struct{}{}:
+	case <-r.Context().Done():
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("%w: %w", models.ErrServerBusy, r.Context().Err()))
+
+		return
+	}
+
+	company, err := s.lookup(r.Context(), name, strings.TrimSpace(request.Country))
+	<-s.workers
+
+	writeJSON(w, lookupStatus(err), scraper.NewCompanyRecord(company, err))
+}
+
+// lookupStatus maps the outcome of a lookup to an HTTP status: companies without usable
+// contacts are not found, and failures of the search API are reported as a bad gateway
+func lookupStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, models.ErrNoResultsFound), errors.Is(err, models.ErrNoUsableSite), errors.Is(err, models.ErrNoEmailFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrRequestFailed), errors.Is(err, models.ErrNonOKStatus), errors.Is(err, models.ErrDecodeFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, request any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(request); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrInvalidRequestBody, err)
+	}
+
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
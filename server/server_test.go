@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Businge931/company-email-scraper/models"
+	"github.com/Businge931/company-email-scraper/scraper"
+)
+
+// stubLookup finds the companies in the map and fails with models.ErrNoUsableSite for
+// any other name
+func stubLookup(companies map[string]models.CompanyResult) LookupFunc {
+	return func(_ context.Context, companyName, _ string) (models.CompanyResult, error) {
+		company, ok := companies[companyName]
+		if !ok {
+			return models.CompanyResult{
+				Name:     companyName,
+				Attempts: []models.SiteAttempt{{URL: "https://unknown.test/", Err: models.ErrNoEmailFound}},
+			}, fmt.Errorf("%w: %w", models.ErrNoUsableSite, models.ErrNoEmailFound)
+		}
+
+		return company, nil
+	}
+}
+
+var acme = models.CompanyResult{
+	Name:   "Acme",
+	URL:    "https://acme.test/",
+	Email:  "info@acme.test",
+	Emails: []models.RankedEmail{{Address: "info@acme.test", Score: 75, SameDomain: true, Relation: models.DomainSame}},
+	Phones: []string{"+49301234567"},
+	Social: models.SocialProfiles{LinkedIn: "https://www.linkedin.com/company/acme"},
+	Address: models.PostalAddress{
+		Street: "Hauptstr. 1", City: "Berlin", PostalCode: "10115", Country: "DE",
+	},
+	EmailPattern: models.InferredPattern{
+		Pattern: models.PatternFirstDotLast, Domain: "acme.test", Confidence: 0.5, Examples: []string{"jane.doe@acme.test"},
+	},
+	Attempts: []models.SiteAttempt{{URL: "https://acme.test/"}},
+}
+
+func TestHandleLookup(t *testing.T) {
+	type args struct {
+		body string
+	}
+
+	type expected struct {
+		status int
+		body   string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected expected
+	}{
+		{
+			name: "success/Contacts of the company",
+			args: args{body: `{"name": " Acme "}`},
+			expected: expected{
+				status: http.StatusOK,
+				body: `{"name":"Acme","url":"https://acme.test/","email":"info@acme.test",
+					"emails":[{"address":"info@acme.test","score":75,"same_domain":true,"relation":"same"}],
+					"phones":["+49301234567"],"social":{"linkedin":"https://www.linkedin.com/company/acme"},
+					"address":{"street":"Hauptstr. 1","city":"Berlin","postal_code":"10115","country":"DE"},
+					"email_pattern":{"pattern":"first.last","domain":"acme.test","confidence":0.5,"examples":["jane.doe@acme.test"]},
+					"attempts":[{"url":"https://acme.test/"}]}`,
+			},
+		},
+		{
+			name: "error/No usable site",
+			args: args{body: `{"name": "Nobody"}`},
+			expected: expected{
+				status: http.StatusNotFound,
+				body: `{"name":"Nobody","attempts":[{"url":"https://unknown.test/","error":"no email found on the page"}],
+					"error":"no search result yielded an email: no email found on the page"}`,
+			},
+		},
+		{
+			name:     "error/Missing name",
+			args:     args{body: `{"name": "  "}`},
+			expected: expected{status: http.StatusBadRequest, body: `{"error":"company name is required"}`},
+		},
+		{
+			name:     "error/Malformed body",
+			args:     args{body: `{"company": "Acme"}`},
+			expected: expected{status: http.StatusBadRequest},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			api := New(stubLookup(map[string]models.CompanyResult{"Acme": acme}), DefaultOptions())
+
+			recorder := httptest.NewRecorder()
+			api.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(tc.args.body)))
+
+			assert.Equal(t, tc.expected.status, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+			if tc.expected.body != "" {
+				assert.JSONEq(t, tc.expected.body, recorder.Body.String())
+			} else {
+				var response errorResponse
+
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Contains(t, response.Error, models.ErrInvalidRequestBody.Error())
+			}
+		})
+	}
+}
+
+func TestHandleLookupWorkers(t *testing.T) {
+	type ctxKey struct{}
+
+	opts := DefaultOptions()
+	opts.Workers = 1
+
+	api := New(func(ctx context.Context, companyName, country string) (models.CompanyResult, error) {
+		assert.Equal(t, "request", ctx.Value(ctxKey{}), "the lookup runs with the request's context")
+		assert.Equal(t, "DE", country)
+
+		return acme, nil
+	}, opts)
+
+	lookup := func(ctx context.Context) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(`{"name": "Acme", "country": " DE "}`))
+		api.Handler().ServeHTTP(recorder, request.WithContext(ctx))
+
+		return recorder
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	assert.Equal(t, http.StatusOK, lookup(ctx).Code)
+	assert.Empty(t, api.workers, "the worker slot is given back")
+
+	// every worker is busy until the client gives up
+	api.workers <- struct{}{}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	recorder := lookup(timeout)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), models.ErrServerBusy.Error())
+}
+
+func TestLookupStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "Found", expected: http.StatusOK},
+		{name: "No search results", err: models.ErrNoResultsFound, expected: http.StatusNotFound},
+		{name: "No site yielded an email", err: fmt.Errorf("%w: %w", models.ErrNoUsableSite, models.ErrNonOKStatus), expected: http.StatusNotFound},
+		{name: "Search API unreachable", err: fmt.Errorf("%w: timeout", models.ErrRequestFailed), expected: http.StatusBadGateway},
+		{name: "API key missing", err: models.ErrAPIKeyNotSet, expected: http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, lookupStatus(tc.err))
+		})
+	}
+}
+
+// clientFunc answers the requests of a lookup without going to the network
+type clientFunc func(req *http.Request) (*http.Response, error)
+
+func (f clientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+}
+
+// TestNewFromConfigConcurrentLookups runs lookups at the same time through the real
+// search and crawl, so that `go test -race` catches shared state they write to
+func TestNewFromConfigConcurrentLookups(t *testing.T) {
+	viper.Set("serpapi.api_key", "valid_api_key")
+	defer viper.Set("serpapi.api_key", "")
+
+	searchClient := clientFunc(func(req *http.Request) (*http.Response, error) {
+		host := strings.ToLower(req.URL.Query().Get("q")) + ".test"
+
+		return stubResponse(http.StatusOK, `{"organic": [{"link": "https://`+host+`/"}]}`), nil
+	})
+
+	fetchClient := clientFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/" {
+			return stubResponse(http.StatusNotFound, ""), nil
+		}
+
+		return stubResponse(http.StatusOK, `<p>Write to info@`+req.URL.Host+`</p>`), nil
+	})
+
+	handler := NewFromConfig(searchClient, scraper.NewFetchClient(fetchClient), scraper.EmailChecks{}).Handler()
+
+	names := []string{"Acme", "Globex", "Initech", "Umbrella"}
+	recorders := make([]*httptest.ResponseRecorder, len(names))
+
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			recorders[i] = httptest.NewRecorder()
+			handler.ServeHTTP(recorders[i], httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(`{"name": "`+name+`"}`)))
+		}()
+	}
+
+	wg.Wait()
+
+	for i, name := range names {
+		var company struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+
+		assert.Equal(t, http.StatusOK, recorders[i].Code, recorders[i].Body.String())
+		assert.NoError(t, json.Unmarshal(recorders[i].Body.Bytes(), &company))
+		assert.Equal(t, name, company.Name)
+		assert.Equal(t, "info@"+strings.ToLower(name)+".test", company.Email)
+	}
+}
+
+func TestNewFromConfigPassesContext(t *testing.T) {
+	type ctxKey struct{}
+
+	viper.Set("serpapi.api_key", "valid_api_key")
+	defer viper.Set("serpapi.api_key", "")
+
+	var searched, fetched any
+
+	searchClient := clientFunc(func(req *http.Request) (*http.Response, error) {
+		searched = req.Context().Value(ctxKey{})
+
+		return stubResponse(http.StatusOK, `{"organic": [{"link": "https://acme.test/"}]}`), nil
+	})
+
+	fetchClient := clientFunc(func(req *http.Request) (*http.Response, error) {
+		fetched = req.Context().Value(ctxKey{})
+
+		return stubResponse(http.StatusNotFound, ""), nil
+	})
+
+	api := NewFromConfig(searchClient, scraper.NewFetchClient(fetchClient), scraper.EmailChecks{})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(`{"name": "Acme"}`))
+	api.Handler().ServeHTTP(recorder, request.WithContext(ctx))
+
+	assert.Equal(t, "request", searched, "the search runs with the request's context")
+	assert.Equal(t, "request", fetched, "the crawl runs with the request's context")
+}